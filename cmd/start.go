@@ -1,14 +1,17 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/cosmostation/cvms/internal/app/exporter"
 	"github.com/cosmostation/cvms/internal/app/indexer"
+	"github.com/cosmostation/cvms/internal/common"
 	"github.com/cosmostation/cvms/internal/helper/config"
 	"github.com/cosmostation/cvms/internal/helper/logger"
 	"github.com/spf13/cobra"
@@ -17,6 +20,9 @@ import (
 
 var flagSets []*pflag.FlagSet
 
+// max wait for in-flight batches of indexers on shutdown
+const indexerFlushTimeout = 30 * time.Second
+
 func init() {
 	flagSets = []*pflag.FlagSet{
 		ConfigFlag(),
@@ -129,7 +135,11 @@ func StartIndexerCmd() *cobra.Command {
 				return err
 			}
 
-			indexerServer, err := indexer.Build(port, logger, cfg, supportChains)
+			// root context for indexers, it'll cancel in-flight db works on shutdown
+			indexerCtx, cancel := context.WithCancel(ctx)
+			defer cancel()
+
+			indexerServer, err := indexer.Build(indexerCtx, port, logger, cfg, supportChains)
 			if err != nil {
 				return err
 			}
@@ -140,6 +150,13 @@ func StartIndexerCmd() *cobra.Command {
 			go func() {
 				<-sigs
 				logger.Println("Received interrupt signal, shutting down...")
+				// let indexers finish in-flight batches before canceling their db works
+				flushCtx, flushCancel := context.WithTimeout(ctx, indexerFlushTimeout)
+				if err := common.FlushAll(flushCtx); err != nil {
+					logger.Errorf("failed to flush indexers: %s", err)
+				}
+				flushCancel()
+				cancel()
 				if err := indexerServer.Shutdown(ctx); err != nil {
 					logger.Fatalf("Server Shutdown Failed:%+v", err)
 				}
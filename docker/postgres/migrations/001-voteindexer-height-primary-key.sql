@@ -0,0 +1,18 @@
+-- one-time migration for deployments which were created before the optional height sub-partitions.
+-- a primary key of a partitioned table must include the partition keys, so height is added into the primary key
+-- like docker/postgres/schema/02-init-voteindexer.sql. it's a no-op when the primary key already has height.
+-- NOTE: the primary key index is rebuilt on every chain partition under an exclusive lock, so run it in a maintenance window
+--   psql -h $DB_HOST -U $DB_USER -d $DB_NAME -f docker/postgres/migrations/001-voteindexer-height-primary-key.sql
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1
+        FROM pg_index i
+        JOIN pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = ANY (i.indkey)
+        WHERE i.indrelid = 'public.voteindexer'::regclass AND i.indisprimary AND a.attname = 'height'
+    ) THEN
+        ALTER TABLE "public"."voteindexer" DROP CONSTRAINT voteindexer_pkey;
+        ALTER TABLE "public"."voteindexer" ADD PRIMARY KEY ("id", "chain_info_id", "height");
+    END IF;
+END
+$$;
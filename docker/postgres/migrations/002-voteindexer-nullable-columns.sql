@@ -0,0 +1,7 @@
+-- one-time migration for deployments which were created before the nullable voteindexer columns.
+-- the vote indexer only adds a missing column at start, so running it once here avoids the DDL lock on the first start.
+-- it's a no-op when the columns already exist.
+--   psql -h $DB_HOST -U $DB_USER -d $DB_NAME -f docker/postgres/migrations/002-voteindexer-nullable-columns.sql
+ALTER TABLE "public"."voteindexer" ADD COLUMN IF NOT EXISTS "validator_vote_power" BIGINT;
+ALTER TABLE "public"."voteindexer" ADD COLUMN IF NOT EXISTS "raw_flag" SMALLINT;
+ALTER TABLE "public"."voteindexer" ADD COLUMN IF NOT EXISTS "block_hash" TEXT;
@@ -0,0 +1,21 @@
+-- one-time migration for deployments which were created with public.voteindexer_archive and public.voteindexer_evidence.
+-- those names are in the namespace of chain partition tables like public.voteindexer_<chain>,
+-- so they are renamed into public.voteindexer__archive and public.voteindexer__evidence.
+-- it's a no-op when a table was already renamed, and a chain partition table which has the old name is never renamed.
+--   psql -h $DB_HOST -U $DB_USER -d $DB_NAME -f docker/postgres/migrations/003-voteindexer-rename-archive-evidence.sql
+DO $$
+DECLARE
+    suffix TEXT;
+BEGIN
+    FOREACH suffix IN ARRAY ARRAY['archive', 'evidence'] LOOP
+        IF EXISTS (
+            SELECT 1
+            FROM pg_class c
+            JOIN pg_namespace n ON n.oid = c.relnamespace
+            WHERE n.nspname = 'public' AND c.relname = 'voteindexer_' || suffix AND NOT c.relispartition
+        ) AND to_regclass('public.voteindexer__' || suffix) IS NULL THEN
+            EXECUTE format('ALTER TABLE "public".%I RENAME TO %I', 'voteindexer_' || suffix, 'voteindexer__' || suffix);
+        END IF;
+    END LOOP;
+END
+$$;
@@ -21,6 +21,22 @@ CREATE TABLE
         CONSTRAINT uniq_index_name_by_chain_info_id UNIQUE (chain_info_id, index_name)
     );
 
+-- append-only audit trail of index pointer resets for reindexing
+CREATE TABLE
+    IF NOT EXISTS "meta"."index_pointer_history" (
+        "id" BIGINT GENERATED ALWAYS AS IDENTITY,
+        "chain_info_id" INT NOT NULL,
+        "index_name" VARCHAR(255) NOT NULL,
+        "old_pointer" BIGINT NOT NULL,
+        "new_pointer" BIGINT NOT NULL,
+        "reason" TEXT NOT NULL DEFAULT '',
+        "created_at" timestamptz NOT NULL DEFAULT now(),
+        PRIMARY KEY ("id"),
+        CONSTRAINT fk_chain_info_id FOREIGN KEY (chain_info_id) REFERENCES meta.chain_info (id) ON DELETE CASCADE ON UPDATE CASCADE
+    );
+
+CREATE INDEX IF NOT EXISTS index_pointer_history_idx_01 ON meta.index_pointer_history (chain_info_id, index_name, created_at DESC);
+
 -- TODO: move these descriptions into comment
 -- definition) operator address: valoper, consensus address: valcons, proposer address: hex
 -- hex_address       := "C7CAA9535CA625AB0447C307975D12523810715A" -> byte size: 40
@@ -36,6 +52,7 @@ CREATE TABLE
         "hex_address" VARCHAR(40) NOT NULL,
         "operator_address" TEXT NOT NULL,
         "moniker" TEXT NOT NULL, 
+        "voting_power" BIGINT NOT NULL DEFAULT 0,
         CONSTRAINT fk_chain_info_id FOREIGN KEY (chain_info_id) REFERENCES meta.chain_info(id) ON DELETE CASCADE ON UPDATE CASCADE,
         PRIMARY KEY ("id", "chain_info_id"),
         CONSTRAINT uniq_hex_address_by_chain UNIQUE (chain_info_id, hex_address),
@@ -44,6 +61,9 @@ CREATE TABLE
 PARTITION BY
     LIST ("chain_info_id");
 
+-- for existing deployments which were created before voting_power column
+ALTER TABLE "meta"."validator_info" ADD COLUMN IF NOT EXISTS "voting_power" BIGINT NOT NULL DEFAULT 0;
+
 
 -- "moniker": "Cosmostation"
 -- "addr": "bbn1x5wgh6vwye60wv3dtshs9dmqggwfx2ldy7agnk"
@@ -1,4 +1,4 @@
--- status := 0 is NaN(jailed or inactive) 1 is missed, 2 is voted, 3 is proposed
+-- status := 0 is unknown(partial block data) or NaN(jailed or inactive) 1 is missed, 2 is voted, 3 is proposed
 CREATE TABLE IF NOT EXISTS "public"."voteindexer" (
         "id" BIGINT GENERATED ALWAYS AS IDENTITY,
         "chain_info_id" INT NOT NULL,
@@ -6,7 +6,15 @@ CREATE TABLE IF NOT EXISTS "public"."voteindexer" (
         "validator_hex_address_id" INT NOT NULL,
         "status" SMALLINT NOT NULL, 
         "timestamp" timestamptz NOT NULL,
-        PRIMARY KEY ("id", "chain_info_id"),
+        -- voting power snapshot at the height, null means the current voting power in meta.validator_info
+        "validator_vote_power" BIGINT,
+        -- raw block id flag of the signature, null means it wasn't stored
+        "raw_flag" SMALLINT,
+        -- block id hash of the height for detecting reorgs, null means it wasn't stored
+        "block_hash" TEXT,
+        -- NOTE: height is a part of the primary key for the optional height range sub-partitions,
+        -- existing deployments are migrated by docker/postgres/migrations/001-voteindexer-height-primary-key.sql
+        PRIMARY KEY ("id", "chain_info_id", "height"),
         CONSTRAINT fk_chain_info_id FOREIGN KEY (chain_info_id) REFERENCES meta.chain_info (id) ON DELETE CASCADE ON UPDATE CASCADE,
         CONSTRAINT fk_validator_hex_address_id FOREIGN KEY (validator_hex_address_id, chain_info_id) REFERENCES meta.validator_info (id, chain_info_id),
         CONSTRAINT uniq_block_missed_validator_hex_address_by_height UNIQUE ("chain_info_id","height","validator_hex_address_id")
@@ -16,4 +24,27 @@ PARTITION BY
 
 CREATE INDEX IF NOT EXISTS voteindexer_idx_01 ON public.voteindexer (height);
 CREATE INDEX IF NOT EXISTS voteindexer_idx_02 ON public.voteindexer (validator_hex_address_id, height);
-CREATE INDEX IF NOT EXISTS voteindexer_idx_03 ON public.voteindexer USING btree (chain_info_id, validator_hex_address_id, height asc);
\ No newline at end of file
+CREATE INDEX IF NOT EXISTS voteindexer_idx_03 ON public.voteindexer USING btree (chain_info_id, validator_hex_address_id, height asc);
+-- daily vote counts per validator aggregated from rows purged by the time retention, only used when the archive option is enabled
+CREATE TABLE IF NOT EXISTS "public"."voteindexer__archive" (
+        "chain_info_id" INT NOT NULL,
+        "validator_hex_address_id" INT NOT NULL,
+        "day" DATE NOT NULL,
+        "total" BIGINT NOT NULL,
+        "missed" BIGINT NOT NULL,
+        PRIMARY KEY ("chain_info_id", "validator_hex_address_id", "day"),
+        CONSTRAINT fk_chain_info_id FOREIGN KEY (chain_info_id) REFERENCES meta.chain_info (id) ON DELETE CASCADE ON UPDATE CASCADE
+    );
+
+-- slashable evidences like double signing by validators
+CREATE TABLE IF NOT EXISTS "public"."voteindexer__evidence" (
+        "id" BIGINT GENERATED ALWAYS AS IDENTITY,
+        "chain_info_id" INT NOT NULL,
+        "height" BIGINT NOT NULL,
+        "validator_hex_address_id" INT NOT NULL,
+        "evidence_type" TEXT NOT NULL,
+        "created_at" timestamptz NOT NULL DEFAULT now(),
+        PRIMARY KEY ("id"),
+        CONSTRAINT fk_chain_info_id FOREIGN KEY (chain_info_id) REFERENCES meta.chain_info (id) ON DELETE CASCADE ON UPDATE CASCADE,
+        CONSTRAINT uniq_evidence_by_height UNIQUE ("chain_info_id","height","validator_hex_address_id","evidence_type")
+    );
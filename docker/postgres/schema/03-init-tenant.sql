@@ -0,0 +1,103 @@
+-- tenant schema for the vote indexer, see DB_SCHEMA in .resource/.env.example
+-- meta tables and voteindexer tables of the tenant are created in the same schema, like:
+--   SELECT meta.create_voteindexer_tenant_schema('team_a');
+CREATE OR REPLACE FUNCTION meta.create_voteindexer_tenant_schema(tenant TEXT) RETURNS VOID AS $$
+BEGIN
+    EXECUTE format('CREATE SCHEMA IF NOT EXISTS %I', tenant);
+
+    EXECUTE format('
+    CREATE TABLE IF NOT EXISTS %1$I."chain_info" (
+        "id" bigserial,
+        "chain_name" VARCHAR(255) NOT NULL,
+        "mainnet" BOOLEAN NOT NULL,
+        "chain_id" VARCHAR(255) NOT NULL,
+        PRIMARY KEY ("id"),
+        UNIQUE ("chain_id", "chain_name")
+    )', tenant);
+
+    EXECUTE format('
+    CREATE TABLE IF NOT EXISTS %1$I."index_pointer" (
+        "id" INT GENERATED ALWAYS AS IDENTITY,
+        "chain_info_id" INT NOT NULL,
+        "index_name" VARCHAR(255) NOT NULL,
+        "pointer" BIGINT NOT NULL,
+        PRIMARY KEY ("id", "chain_info_id"),
+        CONSTRAINT fk_chain_info_id FOREIGN KEY (chain_info_id) REFERENCES %1$I.chain_info (id) ON DELETE CASCADE ON UPDATE CASCADE,
+        CONSTRAINT uniq_index_name_by_chain_info_id UNIQUE (chain_info_id, index_name)
+    )', tenant);
+
+    EXECUTE format('
+    CREATE TABLE IF NOT EXISTS %1$I."index_pointer_history" (
+        "id" BIGINT GENERATED ALWAYS AS IDENTITY,
+        "chain_info_id" INT NOT NULL,
+        "index_name" VARCHAR(255) NOT NULL,
+        "old_pointer" BIGINT NOT NULL,
+        "new_pointer" BIGINT NOT NULL,
+        "reason" TEXT NOT NULL DEFAULT '''',
+        "created_at" timestamptz NOT NULL DEFAULT now(),
+        PRIMARY KEY ("id"),
+        CONSTRAINT fk_chain_info_id FOREIGN KEY (chain_info_id) REFERENCES %1$I.chain_info (id) ON DELETE CASCADE ON UPDATE CASCADE
+    )', tenant);
+    EXECUTE format('CREATE INDEX IF NOT EXISTS index_pointer_history_idx_01 ON %1$I.index_pointer_history (chain_info_id, index_name, created_at DESC)', tenant);
+
+    EXECUTE format('
+    CREATE TABLE IF NOT EXISTS %1$I."validator_info" (
+        "id" BIGINT GENERATED ALWAYS AS IDENTITY,
+        "chain_info_id" INT NOT NULL,
+        "hex_address" VARCHAR(40) NOT NULL,
+        "operator_address" TEXT NOT NULL,
+        "moniker" TEXT NOT NULL,
+        "voting_power" BIGINT NOT NULL DEFAULT 0,
+        CONSTRAINT fk_chain_info_id FOREIGN KEY (chain_info_id) REFERENCES %1$I.chain_info(id) ON DELETE CASCADE ON UPDATE CASCADE,
+        PRIMARY KEY ("id", "chain_info_id"),
+        CONSTRAINT uniq_hex_address_by_chain UNIQUE (chain_info_id, hex_address),
+        CONSTRAINT uniq_operator_address_by_chain UNIQUE (chain_info_id, operator_address)
+    )
+    PARTITION BY LIST ("chain_info_id")', tenant);
+
+    EXECUTE format('
+    CREATE TABLE IF NOT EXISTS %1$I."voteindexer" (
+        "id" BIGINT GENERATED ALWAYS AS IDENTITY,
+        "chain_info_id" INT NOT NULL,
+        "height" BIGINT NOT NULL,
+        "validator_hex_address_id" INT NOT NULL,
+        "status" SMALLINT NOT NULL,
+        "timestamp" timestamptz NOT NULL,
+        "validator_vote_power" BIGINT,
+        "raw_flag" SMALLINT,
+        "block_hash" TEXT,
+        PRIMARY KEY ("id", "chain_info_id", "height"),
+        CONSTRAINT fk_chain_info_id FOREIGN KEY (chain_info_id) REFERENCES %1$I.chain_info (id) ON DELETE CASCADE ON UPDATE CASCADE,
+        CONSTRAINT fk_validator_hex_address_id FOREIGN KEY (validator_hex_address_id, chain_info_id) REFERENCES %1$I.validator_info (id, chain_info_id),
+        CONSTRAINT uniq_block_missed_validator_hex_address_by_height UNIQUE ("chain_info_id","height","validator_hex_address_id")
+    )
+    PARTITION BY LIST ("chain_info_id")', tenant);
+    EXECUTE format('CREATE INDEX IF NOT EXISTS voteindexer_idx_01 ON %1$I.voteindexer (height)', tenant);
+    EXECUTE format('CREATE INDEX IF NOT EXISTS voteindexer_idx_02 ON %1$I.voteindexer (validator_hex_address_id, height)', tenant);
+    EXECUTE format('CREATE INDEX IF NOT EXISTS voteindexer_idx_03 ON %1$I.voteindexer USING btree (chain_info_id, validator_hex_address_id, height asc)', tenant);
+
+    EXECUTE format('
+    CREATE TABLE IF NOT EXISTS %1$I."voteindexer__archive" (
+        "chain_info_id" INT NOT NULL,
+        "validator_hex_address_id" INT NOT NULL,
+        "day" DATE NOT NULL,
+        "total" BIGINT NOT NULL,
+        "missed" BIGINT NOT NULL,
+        PRIMARY KEY ("chain_info_id", "validator_hex_address_id", "day"),
+        CONSTRAINT fk_chain_info_id FOREIGN KEY (chain_info_id) REFERENCES %1$I.chain_info (id) ON DELETE CASCADE ON UPDATE CASCADE
+    )', tenant);
+
+    EXECUTE format('
+    CREATE TABLE IF NOT EXISTS %1$I."voteindexer__evidence" (
+        "id" BIGINT GENERATED ALWAYS AS IDENTITY,
+        "chain_info_id" INT NOT NULL,
+        "height" BIGINT NOT NULL,
+        "validator_hex_address_id" INT NOT NULL,
+        "evidence_type" TEXT NOT NULL,
+        "created_at" timestamptz NOT NULL DEFAULT now(),
+        PRIMARY KEY ("id"),
+        CONSTRAINT fk_chain_info_id FOREIGN KEY (chain_info_id) REFERENCES %1$I.chain_info (id) ON DELETE CASCADE ON UPDATE CASCADE,
+        CONSTRAINT uniq_evidence_by_height UNIQUE ("chain_info_id","height","validator_hex_address_id","evidence_type")
+    )', tenant);
+END;
+$$ LANGUAGE plpgsql;
@@ -1,17 +1,21 @@
 package indexer
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/cosmostation/cvms/internal/common"
+	indexertypes "github.com/cosmostation/cvms/internal/common/indexer/types"
 	"github.com/cosmostation/cvms/internal/helper/config"
 	dbhelper "github.com/cosmostation/cvms/internal/helper/db"
 	"github.com/sirupsen/logrus"
 )
 
-func Build(port string, l *logrus.Logger, cfg *config.MonitoringConfig, sc *config.SupportChains) (
+func Build(ctx context.Context, port string, l *logrus.Logger, cfg *config.MonitoringConfig, sc *config.SupportChains) (
 	/* prometheus indexer server */ *http.Server,
 	/* unexpected error */ error,
 ) {
@@ -53,13 +57,34 @@ func Build(port string, l *logrus.Logger, cfg *config.MonitoringConfig, sc *conf
 		return nil, err
 	}
 
+	// optional server-side statement timeout like 30s, empty means the sql timeout of repositories and 0 disables it
+	// NOTE: it's the same as the client-side timeout by default, so that the server aborts a query which the client gave up
+	dbCfg.StatementTimeout = indexertypes.SQLQueryMaxDuration
+	if st := os.Getenv("DB_STATEMENT_TIMEOUT"); st != "" {
+		dbCfg.StatementTimeout, err = time.ParseDuration(st)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	idb, err := common.NewIndexerDB(dbCfg)
 	if err != nil {
 		return nil, err
 	}
 	idb.SetRetentionTime(rt)
+	// optional tenant schema, empty means the default public and meta schemas
+	idb.SetSchema(os.Getenv("DB_SCHEMA"))
+
+	// optional heights per sub-partition for new chains like 1000000, empty means no sub-partitions
+	if hpi := os.Getenv("DB_HEIGHT_PARTITION_INTERVAL"); hpi != "" {
+		interval, err := strconv.ParseInt(hpi, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		idb.SetHeightPartitionInterval(interval)
+	}
 
-	err = register(app, factory, l, idb, cfg, sc)
+	err = register(ctx, app, factory, l, idb, cfg, sc)
 	if err != nil {
 		return nil, err
 	}
@@ -55,5 +55,7 @@ func buildPrometheusHandler(registry prometheus.Gatherer, logger promhttp.Logger
 		ErrorLog:      logger,
 		ErrorHandling: promhttp.ContinueOnError,
 		Timeout:       time.Second * 5,
+		// exemplars are only exposed in the OpenMetrics format
+		EnableOpenMetrics: true,
 	})
 }
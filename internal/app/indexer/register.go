@@ -1,6 +1,7 @@
 package indexer
 
 import (
+	"context"
 	"strconv"
 
 	"github.com/cosmostation/cvms/internal/common"
@@ -11,7 +12,7 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-func register(m common.Mode, f promauto.Factory, l *logrus.Logger, idb *common.IndexerDB, mc *config.MonitoringConfig, sc *config.SupportChains) error {
+func register(ctx context.Context, m common.Mode, f promauto.Factory, l *logrus.Logger, idb *common.IndexerDB, mc *config.MonitoringConfig, sc *config.SupportChains) error {
 	l.Infof("supported packages for indexer application: %v", common.IndexPackages)
 	for _, cc := range mc.ChainConfigs {
 		chain := sc.Chains[cc.ChainID]
@@ -26,7 +27,7 @@ func register(m common.Mode, f promauto.Factory, l *logrus.Logger, idb *common.I
 			// only register indexer packages among config packages
 			if ok := helper.Contains(common.IndexPackages, pkg); ok {
 				// all package is going to register
-				err := selectPackage(m, f, l, idb, mainnet, chainID, chainName, pkg, protocolType, isConsumer, cc, mc.Monikers)
+				err := selectPackage(ctx, m, f, l, idb, mainnet, chainID, chainName, pkg, protocolType, isConsumer, cc, mc.Monikers)
 				if err != nil {
 					l.WithField("package", pkg).WithField("chain", chainName).WithField("chain_id", chainID).
 						Errorf("this package was failed to start while initiating, so that the package will be skipped: %s", err)
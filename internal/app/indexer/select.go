@@ -1,6 +1,8 @@
 package indexer
 
 import (
+	"context"
+
 	"github.com/cosmostation/cvms/internal/common"
 	"github.com/cosmostation/cvms/internal/helper"
 	"github.com/cosmostation/cvms/internal/helper/config"
@@ -14,7 +16,7 @@ import (
 )
 
 func selectPackage(
-	m common.Mode, f promauto.Factory, l *logrus.Logger,
+	ctx context.Context, m common.Mode, f promauto.Factory, l *logrus.Logger,
 	idb *common.IndexerDB, mainnet bool, chainID, chainName, pkg, protocolType string,
 	isConsumer bool,
 	cc config.ChainConfig, monikers []string,
@@ -47,6 +49,11 @@ func selectPackage(
 		}
 	}
 
+	// NOTE: only the voteindexer package resolves its tables in the tenant schema
+	if idb.Schema != "" && pkg != "voteindexer" {
+		return errors.Errorf("%s package doesn't support the tenant schema %s", pkg, idb.Schema)
+	}
+
 	switch {
 	case pkg == "voteindexer":
 		endpoints := common.Endpoints{RPCs: validRPCs, CheckRPC: true, APIs: validAPIs, CheckAPI: true}
@@ -55,6 +62,7 @@ func selectPackage(
 			return errors.Wrap(err, common.ErrFailedToBuildPackager)
 		}
 		p.SetIndexerDB(idb)
+		p.SetContext(ctx)
 		if isConsumer {
 			providerEndpoints := common.Endpoints{RPCs: providerRPCs, CheckRPC: true, APIs: providerAPIs, CheckAPI: true}
 			p.SetAddtionalEndpoints(providerEndpoints)
@@ -72,6 +80,7 @@ func selectPackage(
 			return errors.Wrap(err, common.ErrFailedToBuildPackager)
 		}
 		p.SetIndexerDB(idb)
+		p.SetContext(ctx)
 		if isConsumer {
 			providerEndpoints := common.Endpoints{RPCs: providerRPCs, CheckRPC: true, APIs: providerAPIs, CheckAPI: true}
 			p.SetAddtionalEndpoints(providerEndpoints)
@@ -89,6 +98,7 @@ func selectPackage(
 			return errors.Wrap(err, common.ErrFailedToBuildPackager)
 		}
 		p.SetIndexerDB(idb)
+		p.SetContext(ctx)
 		if isConsumer {
 			providerEndpoints := common.Endpoints{RPCs: providerRPCs, CheckRPC: true, APIs: providerAPIs, CheckAPI: true}
 			p.SetAddtionalEndpoints(providerEndpoints)
@@ -106,6 +116,7 @@ func selectPackage(
 			return errors.Wrap(err, common.ErrFailedToBuildPackager)
 		}
 		p.SetIndexerDB(idb)
+		p.SetContext(ctx)
 		if isConsumer {
 			providerEndpoints := common.Endpoints{RPCs: providerRPCs, CheckRPC: true, APIs: providerAPIs, CheckAPI: true}
 			p.SetAddtionalEndpoints(providerEndpoints)
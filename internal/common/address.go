@@ -0,0 +1,29 @@
+package common
+
+import (
+	"encoding/hex"
+	"strings"
+
+	sdkhelper "github.com/cosmostation/cvms/internal/helper/sdk"
+	"github.com/pkg/errors"
+)
+
+// ConsHexToBech32 converts the consensus hex address like validator_info.hex_address into bech32 address with the prefix.
+// for example, the prefix is 'cosmosvalcons' for cosmoshub
+func ConsHexToBech32(hexAddress, prefix string) (string, error) {
+	if prefix == "" {
+		return "", errors.New("bech32 prefix is empty")
+	}
+
+	bz, err := hex.DecodeString(strings.TrimPrefix(hexAddress, "0x"))
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to decode %s hex address", hexAddress)
+	}
+
+	address, err := sdkhelper.ConvertAndEncode(prefix, bz)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to encode %s hex address into bech32", hexAddress)
+	}
+
+	return address, nil
+}
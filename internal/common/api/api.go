@@ -48,6 +48,21 @@ func GetBlock(c common.CommonClient, height int64) (
 	/* last commit block height*/ int64,
 	/* block validators signatures */ []types.Signature,
 	error,
+) {
+	blockHeight, blockTimeStamp, blockProposerAddress, blockTxs, lastCommitBlockHeight, blockSignatures, _, err := GetBlockWithHash(c, height)
+	return blockHeight, blockTimeStamp, blockProposerAddress, blockTxs, lastCommitBlockHeight, blockSignatures, err
+}
+
+// query a new block same as GetBlock with the block id hash of the block for detecting reorgs
+func GetBlockWithHash(c common.CommonClient, height int64) (
+	/* block height */ int64,
+	/* block timestamp */ time.Time,
+	/* block proposer addrss */ string,
+	/* block txs */ []types.Tx,
+	/* last commit block height*/ int64,
+	/* block validators signatures */ []types.Signature,
+	/* block hash */ string,
+	error,
 ) {
 	// init context
 	ctx, cancel := context.WithTimeout(context.Background(), common.Timeout)
@@ -58,18 +73,23 @@ func GetBlock(c common.CommonClient, height int64) (
 
 	resp, err := requester.Get(types.CosmosBlockQueryPath(height))
 	if err != nil {
-		return 0, time.Time{}, "", nil, 0, nil, errors.Errorf("rpc call is failed from %s: %s", resp.Request.URL, err)
+		return 0, time.Time{}, "", nil, 0, nil, "", errors.Errorf("rpc call is failed from %s: %s", resp.Request.URL, err)
 	}
 	if resp.StatusCode() != http.StatusOK {
-		return 0, time.Time{}, "", nil, 0, nil, errors.Errorf("stanage status code from %s: [%d]", resp.Request.URL, resp.StatusCode())
+		return 0, time.Time{}, "", nil, 0, nil, "", errors.Errorf("stanage status code from %s: [%d]", resp.Request.URL, resp.StatusCode())
 	}
 
 	blockHeight, blockTimeStamp, blockProposerAddress, blockTxs, lastCommitBlockHeight, blockSignatures, err := parser.CosmosBlockParser(resp.Body())
 	if err != nil {
-		return 0, time.Time{}, "", nil, 0, nil, errors.Wrapf(err, "got data, but failed to parse the data")
+		return 0, time.Time{}, "", nil, 0, nil, "", errors.Wrapf(err, "got data, but failed to parse the data")
+	}
+
+	blockHash, err := parser.CosmosBlockHashParser(resp.Body())
+	if err != nil {
+		return 0, time.Time{}, "", nil, 0, nil, "", errors.Wrapf(err, "got data, but failed to parse the block hash")
 	}
 
-	return blockHeight, blockTimeStamp, blockProposerAddress, blockTxs, lastCommitBlockHeight, blockSignatures, nil
+	return blockHeight, blockTimeStamp, blockProposerAddress, blockTxs, lastCommitBlockHeight, blockSignatures, blockHash, nil
 }
 
 // query cosmos validators on each a new block
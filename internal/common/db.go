@@ -23,6 +23,10 @@ import (
 type IndexerDB struct {
 	*bun.DB
 	RetentionPeriod string
+	// optional tenant schema for partition tables and meta joins, empty means public and meta schemas
+	Schema string
+	// optional heights per sub-partition of new chain partitions, 0 means no sub-partitions
+	HeightPartitionInterval int64
 }
 
 type IndexerDBConfig struct {
@@ -32,6 +36,10 @@ type IndexerDBConfig struct {
 	User     string `toml:"user"`
 	Password string `toml:"password"`
 	Timeout  int64  `toml:"db_timeout"`
+	// optional postgres statement_timeout of every session, the server aborts queries over it even when a cancel isn't propagated.
+	// 0 means disabled, and it should be equal to or longer than the sql timeout of repositories.
+	// the indexer app sets it into the sql timeout of repositories by default
+	StatementTimeout time.Duration `toml:"statement_timeout"`
 }
 
 func NewIndexerDB(cfg IndexerDBConfig) (*IndexerDB, error) {
@@ -51,13 +59,21 @@ func NewIndexerDB(cfg IndexerDBConfig) (*IndexerDB, error) {
 	)
 	timeoutDuration := time.Second * time.Duration(timeout)
 
-	// initiate db
-	sqldb := sql.OpenDB(pgdriver.NewConnector(
+	connectorOptions := []pgdriver.Option{
 		pgdriver.WithDSN(dsn),
 		pgdriver.WithTimeout(timeoutDuration),
 		pgdriver.WithDialTimeout(timeoutDuration),
 		pgdriver.WithReadTimeout(timeoutDuration),
-	))
+	}
+	// NOTE: conn params are sent in the startup message, so the timeout is set on every new session of the pool
+	if cfg.StatementTimeout > 0 {
+		connectorOptions = append(connectorOptions, pgdriver.WithConnParams(map[string]interface{}{
+			"statement_timeout": cfg.StatementTimeout.Milliseconds(),
+		}))
+	}
+
+	// initiate db
+	sqldb := sql.OpenDB(pgdriver.NewConnector(connectorOptions...))
 
 	db := bun.NewDB(sqldb, pgdialect.New())
 	db.AddQueryHook(bundebug.NewQueryHook(
@@ -85,6 +101,14 @@ func (db *IndexerDB) SetRetentionTime(retentionPeriod string) {
 	db.RetentionPeriod = retentionPeriod
 }
 
+func (db *IndexerDB) SetSchema(schema string) {
+	db.Schema = schema
+}
+
+func (db *IndexerDB) SetHeightPartitionInterval(interval int64) {
+	db.HeightPartitionInterval = interval
+}
+
 // TODO: currently, we don't use this helper.DB
 func (db *IndexerDB) CloseConn() error {
 	return db.DB.Close()
@@ -0,0 +1,56 @@
+package common
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Flusher persists in-flight works of an indexer before the root context is canceled on shutdown
+type Flusher interface {
+	Flush(ctx context.Context) error
+}
+
+var flushers = struct {
+	sync.Mutex
+	list []Flusher
+}{}
+
+// RegisterFlusher adds the flusher to be called by FlushAll on shutdown
+func RegisterFlusher(f Flusher) {
+	flushers.Lock()
+	defer flushers.Unlock()
+	flushers.list = append(flushers.list, f)
+}
+
+// FlushAll calls every registered flusher concurrently and waits for them until the context is done
+func FlushAll(ctx context.Context) error {
+	flushers.Lock()
+	list := make([]Flusher, len(flushers.list))
+	copy(list, flushers.list)
+	flushers.Unlock()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	errMessages := make([]string, 0)
+	for _, f := range list {
+		wg.Add(1)
+		go func(f Flusher) {
+			defer wg.Done()
+			if err := f.Flush(ctx); err != nil {
+				mu.Lock()
+				errMessages = append(errMessages, err.Error())
+				mu.Unlock()
+			}
+		}(f)
+	}
+	wg.Wait()
+
+	if len(errMessages) > 0 {
+		return errors.Errorf("failed to flush %d indexers: %s", len(errMessages), strings.Join(errMessages, "; "))
+	}
+
+	return nil
+}
@@ -1,6 +1,7 @@
 package common
 
 import (
+	"context"
 	"time"
 
 	indexertypes "github.com/cosmostation/cvms/internal/common/indexer/types"
@@ -16,10 +17,37 @@ const (
 	IndexPointerBlockTimestampMetricName = "latest_index_pointer_block_timestamp"
 	LatestBlockHeightMetricName          = "latest_block_height"
 	RecentMissCounterMetricName          = "recent_miss_counter"
+	InsertDurationMetricName             = "insert_duration_seconds"
+	IndexPointerLagMetricName            = "pointer_lag"
+	RecentCommittedMetricName            = "recent_committed"
+	RecentProposedMetricName             = "recent_proposed"
+	RecentMissedMetricName               = "recent_missed"
+	RecentMissedPowerMetricName          = "recent_missed_power"
+	LastSuccessTimestampMetricName       = "last_success_timestamp"
+	ErrorsTotalMetricName                = "errors_total"
+	InsertedTotalMetricName              = "inserted_total"
+	RetentionDeletedTotalMetricName      = "retention_deleted_total"
+	RetentionDurationMetricName          = "retention_duration_seconds"
+	BlocksSinceLastProposalMetricName    = "blocks_since_last_proposal"
+	TxRollbackTotalMetricName            = "tx_rollback_total"
+	IndexCompletenessMetricName          = "index_completeness_ratio"
+	ValidatorEvidenceTotalMetricName     = "validator_evidence_total"
+	PartitionRowsMetricName              = "partition_rows"
+	SecondsSinceLastBlockMetricName      = "seconds_since_last_block"
+	ProposalDeficitRatioMetricName       = "proposal_deficit_ratio"
+)
+
+// phase label values for indexer errors metric
+const (
+	InsertPhase = "insert"
+	SelectPhase = "select"
+	DeletePhase = "delete"
 )
 
 type Indexer struct {
 	CommonApp
+	// root context of the application, it'll be canceled on shutdown
+	Ctx          context.Context
 	ChainName    string
 	Mainnet      bool
 	ChainID      string
@@ -35,6 +63,9 @@ type Indexer struct {
 	Factory       promauto.Factory
 	MetricsMap    map[string]prometheus.Gauge
 	MetricsVecMap map[string]*prometheus.GaugeVec
+	HistogramMap  map[string]prometheus.Histogram
+	CounterMap    map[string]prometheus.Counter
+	CounterVecMap map[string]*prometheus.CounterVec
 	RootLabels    prometheus.Labels
 	PackageLabels prometheus.Labels
 }
@@ -69,8 +100,15 @@ func NewIndexer(p Packager, subsystem string, chainID string) *Indexer {
 		}
 	}
 
+	// NOTE: indexers without any root context never be canceled
+	ctx := p.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
 	return &Indexer{
 		CommonApp: app,
+		Ctx:       ctx,
 		ChainName: p.ChainName,
 		Mainnet:   p.Mainnet,
 		ChainID:   p.ChainID,
@@ -85,6 +123,9 @@ func NewIndexer(p Packager, subsystem string, chainID string) *Indexer {
 		Factory:       p.Factory,
 		MetricsMap:    map[string]prometheus.Gauge{},
 		MetricsVecMap: map[string]*prometheus.GaugeVec{},
+		HistogramMap:  map[string]prometheus.Histogram{},
+		CounterMap:    map[string]prometheus.Counter{},
+		CounterVecMap: map[string]*prometheus.CounterVec{},
 		RootLabels:    BuildRootLabels(p),
 		PackageLabels: BuildPackageLabels(p),
 	}
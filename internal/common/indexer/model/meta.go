@@ -2,12 +2,13 @@ package model
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/uptrace/bun"
 )
 
 type FinalityProviderInfo struct {
-	bun.BaseModel   `bun:"table:meta.finality_provider_info"`
+	bun.BaseModel   `bun:"table:meta.finality_provider_info,alias:finality_provider_info"`
 	ID              int64  `bun:"id,pk,autoincrement"`
 	ChainInfoID     int64  `bun:"chain_info_id,pk,notnull"`
 	Moniker         string `bun:"moniker"`
@@ -26,27 +27,29 @@ func (vi FinalityProviderInfo) String() string {
 }
 
 type ValidatorInfo struct {
-	bun.BaseModel `bun:"table:meta.validator_info"`
+	bun.BaseModel `bun:"table:meta.validator_info,alias:validator_info"`
 
 	ID              int64  `bun:"id,pk,autoincrement"`
 	ChainInfoID     int64  `bun:"chain_info_id,pk,notnull"`
 	HexAddress      string `bun:"hex_address,unique:uniq_hex_address_by_chain"`
 	OperatorAddress string `bun:"operator_address,unique:uniq_operator_address_by_chain"`
 	Moniker         string `bun:"moniker"`
+	VotingPower     int64  `bun:"voting_power,notnull"`
 }
 
 func (vi ValidatorInfo) String() string {
-	return fmt.Sprintf("ValidatorInfo<%d %d %s %s %s>",
+	return fmt.Sprintf("ValidatorInfo<%d %d %s %s %s %d>",
 		vi.ID,
 		vi.ChainInfoID,
 		vi.HexAddress,
 		vi.OperatorAddress,
 		vi.Moniker,
+		vi.VotingPower,
 	)
 }
 
 type ChainInfo struct {
-	bun.BaseModel `bun:"table:meta.chain_info"`
+	bun.BaseModel `bun:"table:meta.chain_info,alias:chain_info"`
 
 	ID        int64  `bun:"id,pk,autoincrement"`
 	ChainName string `bun:"chain_name"`
@@ -64,7 +67,7 @@ func (ci ChainInfo) String() string {
 }
 
 type IndexPointer struct {
-	bun.BaseModel `bun:"table:meta.index_pointer"`
+	bun.BaseModel `bun:"table:meta.index_pointer,alias:index_pointer"`
 
 	ID          int64  `bun:"id,pk,autoincrement"`
 	ChainInfoID int64  `bun:"chain_info_id,pk,notnull"`
@@ -80,3 +83,27 @@ func (ip IndexPointer) String() string {
 		ip.Pointer,
 	)
 }
+
+type IndexPointerHistory struct {
+	bun.BaseModel `bun:"table:meta.index_pointer_history,alias:index_pointer_history"`
+
+	ID          int64     `bun:"id,pk,autoincrement"`
+	ChainInfoID int64     `bun:"chain_info_id,notnull"`
+	IndexName   string    `bun:"index_name,notnull"`
+	OldPointer  int64     `bun:"old_pointer,notnull"`
+	NewPointer  int64     `bun:"new_pointer,notnull"`
+	Reason      string    `bun:"reason,notnull"`
+	CreatedAt   time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+}
+
+func (iph IndexPointerHistory) String() string {
+	return fmt.Sprintf("IndexPointerHistory<%d %d %s %d->%d %s %d>",
+		iph.ID,
+		iph.ChainInfoID,
+		iph.IndexName,
+		iph.OldPointer,
+		iph.NewPointer,
+		iph.Reason,
+		iph.CreatedAt.Unix(),
+	)
+}
@@ -20,6 +20,7 @@ func (repo *MetaRepository) InsertChainInfo(chainName, chainID string, isMainnet
 	_, err := repo.
 		NewInsert().
 		Model(chainInfo).
+		ModelTableExpr(repo.metaTableExpr("chain_info")).
 		ExcludeColumn("id").
 		Returning("*").
 		Exec(ctx)
@@ -38,7 +39,7 @@ func (repo *MetaRepository) SelectChainInfoIDByChainID(chainID string) (int64, e
 	chainInfo := &model.ChainInfo{}
 	err := repo.
 		NewSelect().
-		ModelTableExpr("meta.chain_info").
+		ModelTableExpr(repo.metaTableExpr("chain_info")).
 		ColumnExpr("*").
 		Where("chain_id = ?", chainID).
 		Scan(ctx, chainInfo)
@@ -48,3 +49,23 @@ func (repo *MetaRepository) SelectChainInfoIDByChainID(chainID string) (int64, e
 
 	return chainInfo.ID, nil
 }
+
+// SelectIndexedChains returns all chains in the meta.chain_info table.
+// NOTE: the meta schema doesn't have bech32 prefixes, chain_name is the only human readable name for now
+func (repo *MetaRepository) SelectIndexedChains() ([]model.ChainInfo, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), repo.defaultTimeout)
+	defer cancel()
+
+	chainInfoList := make([]model.ChainInfo, 0)
+	err := repo.
+		NewSelect().
+		Model(&chainInfoList).
+		ModelTableExpr(repo.metaTableExpr("chain_info")).
+		Order("id ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to select indexed chains")
+	}
+
+	return chainInfoList, nil
+}
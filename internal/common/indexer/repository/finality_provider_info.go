@@ -2,11 +2,10 @@ package repository
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/cosmostation/cvms/internal/common/indexer/model"
 
-	"github.com/cosmostation/cvms/internal/helper"
+	dbhelper "github.com/cosmostation/cvms/internal/helper/db"
 	"github.com/pkg/errors"
 	"github.com/uptrace/bun"
 )
@@ -21,6 +20,7 @@ func (repo *MetaRepository) CreateFinalityProviderInfoPartitionTableByChainID(ch
 	err := repo.
 		NewSelect().
 		Model(ci).
+		ModelTableExpr(repo.metaTableExpr("chain_info")).
 		Column("id").
 		Where("chain_id = ?", chainID).
 		Scan(ctx)
@@ -28,13 +28,7 @@ func (repo *MetaRepository) CreateFinalityProviderInfoPartitionTableByChainID(ch
 		return errors.Wrapf(err, "failed to select chain_info id by chain_id")
 	}
 
-	tableNameWithSuffix := fmt.Sprintf("meta.%s_%s", finalityProviderInfoTableName, helper.ParseToSchemaName(chainID))
-	query := fmt.Sprintf(
-		`CREATE TABLE IF NOT EXISTS %s PARTITION OF "meta"."%s" FOR VALUES IN ('%d');`,
-		tableNameWithSuffix, finalityProviderInfoTableName, ci.ID,
-	)
-
-	_, err = repo.NewRaw(query).Exec(ctx)
+	_, err = repo.NewRaw(dbhelper.MakeCreateMetaPartitionTableQuery(repo.schema, finalityProviderInfoTableName, chainID, ci.ID)).Exec(ctx)
 	if err != nil {
 		return errors.Wrap(err, "failed to create a new partition table")
 	}
@@ -50,6 +44,7 @@ func (repo *MetaRepository) GetFinalityProviderInfoListByChainInfoID(chainInfoID
 	err := repo.
 		NewSelect().
 		Model(&FinalityProviderInfoList).
+		ModelTableExpr(repo.metaTableExpr("finality_provider_info")).
 		Where("chain_info_id = ?", chainInfoID).
 		Scan(ctx)
 	if err != nil {
@@ -65,6 +60,7 @@ func (repo *MetaRepository) InsertFinalityProviderInfoList(fpInfoList []model.Fi
 
 	_, err := repo.NewInsert().
 		Model(&fpInfoList).
+		ModelTableExpr(repo.metaTableExpr("finality_provider_info")).
 		ExcludeColumn("id").
 		Exec(ctx)
 	if err != nil {
@@ -82,6 +78,7 @@ func (repo *MetaRepository) GetFinalityProviderInfoListByMonikers(chainInfoID in
 	err := repo.
 		NewSelect().
 		Model(&FinalityProviderInfoList).
+		ModelTableExpr(repo.metaTableExpr("finality_provider_info")).
 		ColumnExpr("*").
 		Where("chain_info_id = ?", chainInfoID).
 		Where("moniker in (?)", bun.In(monikers)).
@@ -20,6 +20,7 @@ func (repo *MetaRepository) InitializeIndexPointerByChainID(
 	err := repo.
 		NewSelect().
 		Model(ci).
+		ModelTableExpr(repo.metaTableExpr("chain_info")).
 		Column("id").
 		Where("chain_id = ?", chainID).
 		Scan(ctx)
@@ -31,6 +32,7 @@ func (repo *MetaRepository) InitializeIndexPointerByChainID(
 	exists, err := repo.
 		NewSelect().
 		Model((*model.IndexPointer)(nil)).
+		ModelTableExpr(repo.metaTableExpr("index_pointer")).
 		Where("chain_info_id = ?", ci.ID).
 		Where("index_name = ?", indexTableName).
 		Exists(ctx)
@@ -53,6 +55,7 @@ func (repo *MetaRepository) InitializeIndexPointerByChainID(
 						IndexName:   indexTableName,
 						Pointer:     initPointer,
 					}).
+					ModelTableExpr(repo.metaTableExpr("index_pointer")).
 					On("CONFLICT ON CONSTRAINT uniq_index_name_by_chain_info_id DO NOTHING").
 					Exec(ctx)
 				if err != nil {
@@ -75,6 +78,7 @@ func (repo *MetaRepository) GetLastIndexPointerByIndexTableName(indexTableName s
 	err := repo.
 		NewSelect().
 		Model(&ip).
+		ModelTableExpr(repo.metaTableExpr("index_pointer")).
 		Where("chain_info_id = ?", chainInfoID).
 		Where("index_name = ?", indexTableName).
 		Scan(ctx)
@@ -93,6 +97,7 @@ func (repo *MetaRepository) CheckIndexpoinerAlreadyInitialized(indexTableName st
 	err := repo.
 		NewSelect().
 		Model(&ip).
+		ModelTableExpr(repo.metaTableExpr("index_pointer")).
 		Where("chain_info_id = ?", chainInfoID).
 		Where("index_name = ?", indexTableName).
 		Scan(ctx)
@@ -20,7 +20,7 @@ var (
 
 func TestMain(m *testing.M) {
 	_ = testutil.SetupForTest()
-	metarepo = &MetaRepository{10 * time.Second, testutil.TestDB}
+	metarepo = &MetaRepository{10 * time.Second, testutil.TestDB, ""}
 	prerunForTest()
 	r := m.Run()
 	metarepo.Close()
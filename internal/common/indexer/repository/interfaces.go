@@ -18,6 +18,7 @@ type IMetaRepository interface {
 type IChainInfoRepository interface {
 	InsertChainInfo(chainName, chainID string, IsMainnet bool) (int64, error)
 	SelectChainInfoIDByChainID(chainID string) (int64, error)
+	SelectIndexedChains() ([]model.ChainInfo, error)
 }
 
 // interface for about meta.index_pointer table
@@ -33,6 +34,8 @@ type IValidatorInfoRepository interface {
 	GetValidatorInfoListByChainInfoID(chainInfoID int64) (validatorInfoList []model.ValidatorInfo, err error)
 	InsertValidatorInfoList(validatorInfoList []model.ValidatorInfo) error
 	GetValidatorInfoListByMonikers(chainInfoID int64, monikers []string) ([]model.ValidatorInfo, error)
+	UpsertValidatorInfo(validatorInfo model.ValidatorInfo) error
+	UpdateValidatorInfoVotingPowers(chainInfoID int64, votingPowers map[string]int64) error
 }
 
 // interface for about meta.finality_provider table
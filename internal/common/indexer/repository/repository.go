@@ -14,11 +14,18 @@ import (
 type MetaRepository struct {
 	defaultTimeout time.Duration
 	*bun.DB
+	// tenant schema for partition tables and meta tables, empty means public and meta schemas
+	schema string
+}
+
+// make the model table expression of the meta table in the tenant schema
+func (repo *MetaRepository) metaTableExpr(tableName string) string {
+	return dbhelper.MakeMetaTableExpr(repo.schema, tableName)
 }
 
 // New general repository constructor
 func NewMetaRepository(indexerDB common.IndexerDB) IMetaRepository {
-	return &MetaRepository{common.IndexerSQLDefaultTimeout, indexerDB.DB}
+	return &MetaRepository{common.IndexerSQLDefaultTimeout, indexerDB.DB, indexerDB.Schema}
 }
 
 // Because of using chainInfoID, some users can use wrong chain_info_id about input chainID
@@ -30,6 +37,7 @@ func (repo *MetaRepository) CreatePartitionTable(IndexName, chainID string) erro
 	err := repo.
 		NewSelect().
 		Model(chainInfo).
+		ModelTableExpr(repo.metaTableExpr("chain_info")).
 		Column("id").
 		Where("chain_id = ?", chainID).
 		Scan(ctx)
@@ -37,7 +45,7 @@ func (repo *MetaRepository) CreatePartitionTable(IndexName, chainID string) erro
 		return errors.Wrapf(err, "failed to select chain_info id by chain_id")
 	}
 
-	_, err = repo.NewRaw(dbhelper.MakeCreatePartitionTableQuery(IndexName, chainID, chainInfo.ID)).Exec(ctx)
+	_, err = repo.NewRaw(dbhelper.MakeCreatePartitionTableQueryWithSchema(repo.schema, IndexName, chainID, chainInfo.ID)).Exec(ctx)
 	if err != nil {
 		return errors.Wrap(err, "failed to create a new partition table")
 	}
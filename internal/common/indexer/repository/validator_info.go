@@ -2,11 +2,10 @@ package repository
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/cosmostation/cvms/internal/common/indexer/model"
 
-	"github.com/cosmostation/cvms/internal/helper"
+	dbhelper "github.com/cosmostation/cvms/internal/helper/db"
 	"github.com/pkg/errors"
 	"github.com/uptrace/bun"
 )
@@ -21,6 +20,7 @@ func (repo *MetaRepository) CreateValidatorInfoPartitionTableByChainID(chainID s
 	err := repo.
 		NewSelect().
 		Model(ci).
+		ModelTableExpr(repo.metaTableExpr("chain_info")).
 		Column("id").
 		Where("chain_id = ?", chainID).
 		Scan(ctx)
@@ -28,13 +28,7 @@ func (repo *MetaRepository) CreateValidatorInfoPartitionTableByChainID(chainID s
 		return errors.Wrapf(err, "failed to select chain_info id by chain_id")
 	}
 
-	tableNameWithSuffix := fmt.Sprintf("meta.%s_%s", validatorInfoTableName, helper.ParseToSchemaName(chainID))
-	query := fmt.Sprintf(
-		`CREATE TABLE IF NOT EXISTS %s PARTITION OF "meta"."%s" FOR VALUES IN ('%d');`,
-		tableNameWithSuffix, validatorInfoTableName, ci.ID,
-	)
-
-	_, err = repo.NewRaw(query).Exec(ctx)
+	_, err = repo.NewRaw(dbhelper.MakeCreateMetaPartitionTableQuery(repo.schema, validatorInfoTableName, chainID, ci.ID)).Exec(ctx)
 	if err != nil {
 		return errors.Wrap(err, "failed to create a new partition table")
 	}
@@ -50,6 +44,7 @@ func (repo *MetaRepository) GetValidatorInfoListByChainInfoID(chainInfoID int64)
 	err := repo.
 		NewSelect().
 		Model(&validatorInfoList).
+		ModelTableExpr(repo.metaTableExpr("validator_info")).
 		Where("chain_info_id = ?", chainInfoID).
 		Scan(ctx)
 	if err != nil {
@@ -65,6 +60,7 @@ func (repo *MetaRepository) InsertValidatorInfoList(validatorInfoList []model.Va
 
 	_, err := repo.NewInsert().
 		Model(&validatorInfoList).
+		ModelTableExpr(repo.metaTableExpr("validator_info")).
 		ExcludeColumn("id").
 		Exec(ctx)
 	if err != nil {
@@ -82,6 +78,7 @@ func (repo *MetaRepository) GetValidatorInfoListByMonikers(chainInfoID int64, mo
 	err := repo.
 		NewSelect().
 		Model(&validatorInfoList).
+		ModelTableExpr(repo.metaTableExpr("validator_info")).
 		ColumnExpr("*").
 		Where("chain_info_id = ?", chainInfoID).
 		Where("moniker in (?)", bun.In(monikers)).
@@ -92,3 +89,51 @@ func (repo *MetaRepository) GetValidatorInfoListByMonikers(chainInfoID int64, mo
 
 	return validatorInfoList, nil
 }
+
+// NOTE: hex address is stable for a validator, so that it's used as a conflict key for updating a renamed moniker in place
+func (repo *MetaRepository) UpsertValidatorInfo(validatorInfo model.ValidatorInfo) error {
+	ctx := context.Background()
+	defer ctx.Done()
+
+	_, err := repo.NewInsert().
+		Model(&validatorInfo).
+		ModelTableExpr(repo.metaTableExpr("validator_info")).
+		ExcludeColumn("id").
+		On("CONFLICT ON CONSTRAINT uniq_hex_address_by_chain DO UPDATE").
+		Set("moniker = EXCLUDED.moniker").
+		Exec(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "failed to upsert validator info")
+	}
+
+	return nil
+}
+
+func (repo *MetaRepository) UpdateValidatorInfoVotingPowers(chainInfoID int64, votingPowers map[string]int64) error {
+	ctx := context.Background()
+	defer ctx.Done()
+
+	err := repo.RunInTx(
+		ctx,
+		nil,
+		func(ctx context.Context, tx bun.Tx) error {
+			for hexAddress, votingPower := range votingPowers {
+				_, err := tx.NewUpdate().
+					Model((*model.ValidatorInfo)(nil)).
+					ModelTableExpr(repo.metaTableExpr("validator_info")).
+					Set("voting_power = ?", votingPower).
+					Where("chain_info_id = ?", chainInfoID).
+					Where("hex_address = ?", hexAddress).
+					Exec(ctx)
+				if err != nil {
+					return errors.Wrapf(err, "failed to update voting power of %s", hexAddress)
+				}
+			}
+			return nil
+		})
+	if err != nil {
+		return errors.Wrapf(err, "failed to update validator info voting powers")
+	}
+
+	return nil
+}
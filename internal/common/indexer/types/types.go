@@ -14,8 +14,10 @@ const (
 	UnHealthSleep           = 10 * time.Second
 
 	// SQL Durations
-	SQLQueryMaxDuration         = 10 * time.Second // common query timeout
-	RetentionQuerySleepDuration = 1 * time.Hour    // retention logic
+	SQLQueryMaxDuration            = 10 * time.Second // common query timeout
+	RetentionQuerySleepDuration    = 1 * time.Hour    // retention logic
+	PartitionRowCountSleepDuration = 10 * time.Minute // partition row count metric
+	ProposerMetricsSleepDuration   = 1 * time.Minute  // proposer gap and proposal deficit metrics
 
 	// Fetching Height Logic
 	FetchTimeout                  = 5 * time.Second
@@ -20,4 +20,11 @@ const (
 	BalanceAddressLabel      = "balance_address"
 	UpgradeNameLabel         = "upgrade_name"
 	BTCPKLabel               = "btc_pk"
+	PhaseLabel               = "phase"
+	StatusLabel              = "status"
+	ReasonLabel              = "reason"
+	EvidenceTypeLabel        = "type"
+
+	// exemplar labels
+	TraceIDLabel = "trace_id"
 )
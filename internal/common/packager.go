@@ -1,6 +1,8 @@
 package common
 
 import (
+	"context"
+
 	"github.com/pkg/errors"
 
 	"github.com/cosmostation/cvms/internal/helper/config"
@@ -66,6 +68,7 @@ type OptionPackager struct {
 	// optional for indexers
 	*IndexerDB
 	RetentionPeriod string
+	Context         context.Context
 
 	// optional for consumer chain
 	IsConsumerChain   bool
@@ -119,6 +122,11 @@ func (p *Packager) SetIndexerDB(idxDB *IndexerDB) *Packager {
 	return p
 }
 
+func (p *Packager) SetContext(ctx context.Context) *Packager {
+	p.Context = ctx
+	return p
+}
+
 func packagerValidate(
 	chainID, chainName, protocolType string,
 	endpoints Endpoints) error {
@@ -74,6 +74,29 @@ func CosmosBlockParser(resp []byte) (
 	}
 }
 
+// CosmosBlockHashParser returns the block id hash of the block response
+func CosmosBlockHashParser(resp []byte) (string, error) {
+	var preResult map[string]interface{}
+	if err := json.Unmarshal(resp, &preResult); err != nil {
+		return "", err
+	}
+
+	_, ok := preResult["jsonrpc"].(string)
+	if ok { // v0.34.x
+		var resultV34 types.CosmosV34BlockResponse
+		if err := json.Unmarshal(resp, &resultV34); err != nil {
+			return "", err
+		}
+		return resultV34.Result.BlockID.Hash, nil
+	} else { // tendermint v0.37.x
+		var resultV37 types.CosmosV37BlockResponse
+		if err := json.Unmarshal(resp, &resultV37); err != nil {
+			return "", err
+		}
+		return resultV37.BlockID.Hash, nil
+	}
+}
+
 func CosmosStatusParser(resp []byte) (
 	/* latest block height */ int64,
 	/* latest block timestamp */ time.Time,
@@ -20,6 +20,8 @@ type BlockSummary struct {
 	LastCommitBlockHeight int64
 	BlockSignatures       []Signature
 	CosmosValidators      []CosmosValidator
+	// hex encoded block id hash of the block
+	BlockHash string
 }
 
 const (
@@ -85,8 +87,10 @@ type CosmosV37BlockResponse CosmosBlock
 
 // response of cosmos-sdk based chain block
 type CosmosBlock struct {
-	BlockID interface{} `json:"-"`
-	Block   struct {
+	BlockID struct {
+		Hash string `json:"hash"`
+	} `json:"block_id"`
+	Block struct {
 		Header struct {
 			ChainID         string    `json:"chain_id"`
 			Height          string    `json:"height"`
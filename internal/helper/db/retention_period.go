@@ -3,7 +3,9 @@ package db
 import (
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
+	"unicode"
 )
 
 const (
@@ -18,8 +20,12 @@ func ParseRetentionPeriod(retentionPeriod string) (time.Duration, error) {
 		return maxDuration, nil
 	}
 
-	// Split the value and unit (e.g., "1d" -> "1", "d")
-	value, unit := retentionPeriod[:len(retentionPeriod)-1], retentionPeriod[len(retentionPeriod)-1:]
+	// Split the value and unit (e.g., "1d" -> "1", "d", "3mo" -> "3", "mo")
+	idx := strings.IndexFunc(retentionPeriod, func(r rune) bool { return !unicode.IsDigit(r) })
+	if idx < 0 {
+		return 0, fmt.Errorf("missing unit in retention period: %s", retentionPeriod)
+	}
+	value, unit := retentionPeriod[:idx], retentionPeriod[idx:]
 
 	// Parse the numeric part
 	num, err := strconv.Atoi(value)
@@ -44,11 +50,13 @@ func ParseRetentionPeriod(retentionPeriod string) (time.Duration, error) {
 			return 0, fmt.Errorf("hours out of range: %d (valid range: 1h to 24h)", num)
 		}
 		return time.Duration(-num) * time.Hour, nil
-	case "m": // 1 month ~ 12 months
+	case "mo": // 1 month ~ 12 months
 		if num < 1 || num > 12 {
-			return 0, fmt.Errorf("months out of range: %d (valid range: 1m to 12m)", num)
+			return 0, fmt.Errorf("months out of range: %d (valid range: 1mo to 12mo)", num)
 		}
 		return time.Duration(-num) * 30 * 24 * time.Hour, nil // Assuming 1 month is 30 days
+	case "m": // NOTE: it's ambiguous between minutes and months, so that months must be given as mo
+		return 0, fmt.Errorf("ambiguous unit in retention period: %s (use mo for months)", retentionPeriod)
 	default:
 		return 0, fmt.Errorf("invalid unit in retention period: %s", retentionPeriod)
 	}
@@ -0,0 +1,46 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRetentionPeriod(t *testing.T) {
+	testCases := []struct {
+		retentionPeriod  string
+		expectedDuration time.Duration
+		success          bool
+	}{
+		{retentionPeriod: "1h", expectedDuration: -1 * time.Hour, success: true},
+		{retentionPeriod: "7d", expectedDuration: -7 * 24 * time.Hour, success: true},
+		{retentionPeriod: "1w", expectedDuration: -7 * 24 * time.Hour, success: true},
+		{retentionPeriod: "4w", expectedDuration: -4 * 7 * 24 * time.Hour, success: true},
+		{retentionPeriod: "1mo", expectedDuration: -30 * 24 * time.Hour, success: true},
+		{retentionPeriod: "3mo", expectedDuration: -3 * 30 * 24 * time.Hour, success: true},
+		{retentionPeriod: "12mo", expectedDuration: -12 * 30 * 24 * time.Hour, success: true},
+		{retentionPeriod: PersistenceMode, expectedDuration: maxDuration, success: true},
+		// ambiguous minutes or months
+		{retentionPeriod: "1m", success: false},
+		// out of range
+		{retentionPeriod: "5w", success: false},
+		{retentionPeriod: "13mo", success: false},
+		// invalid format
+		{retentionPeriod: "mo", success: false},
+		{retentionPeriod: "10", success: false},
+		{retentionPeriod: "1y", success: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.retentionPeriod, func(t *testing.T) {
+			duration, err := ParseRetentionPeriod(tc.retentionPeriod)
+			if !tc.success {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedDuration, duration)
+		})
+	}
+}
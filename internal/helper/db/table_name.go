@@ -2,17 +2,66 @@ package db
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/cosmostation/cvms/internal/helper"
 )
 
+const (
+	// default schemas when the indexer db doesn't have any tenant schema
+	DefaultPartitionSchema = "public"
+	DefaultMetaSchema      = "meta"
+)
+
+// NOTE: postgres folds unquoted identifiers to lowercase, so that chain ids like Agoric-3 are normalized into lowercase
+// to make the table name same with the one which was created in the catalog
 func MakePartitionTableName(indexName, chainID string) string {
-	return fmt.Sprintf("public.%s_%s", indexName, helper.ParseToSchemaName(chainID))
+	return MakePartitionTableNameWithSchema(DefaultPartitionSchema, indexName, chainID)
+}
+
+// MakePartitionTableNameWithSchema makes the partition table name in the given schema, empty schema means the default one
+func MakePartitionTableNameWithSchema(schema, indexName, chainID string) string {
+	if schema == "" {
+		schema = DefaultPartitionSchema
+	}
+	return fmt.Sprintf("%s.%s_%s", schema, indexName, strings.ToLower(helper.ParseToSchemaName(chainID)))
 }
 
 func MakeCreatePartitionTableQuery(indexName, chainID string, chainInfoID int64) string {
+	return MakeCreatePartitionTableQueryWithSchema(DefaultPartitionSchema, indexName, chainID, chainInfoID)
+}
+
+func MakeCreatePartitionTableQueryWithSchema(schema, indexName, chainID string, chainInfoID int64) string {
+	if schema == "" {
+		schema = DefaultPartitionSchema
+	}
+	return fmt.Sprintf(
+		`CREATE TABLE IF NOT EXISTS %s PARTITION OF "%s"."%s" FOR VALUES IN ('%d');`,
+		MakePartitionTableNameWithSchema(schema, indexName, chainID), schema, indexName, chainInfoID,
+	)
+}
+
+// MakeMetaTableName makes the meta table name in the given schema, empty schema means the default meta schema
+func MakeMetaTableName(schema, tableName string) string {
+	if schema == "" {
+		schema = DefaultMetaSchema
+	}
+	return fmt.Sprintf("%s.%s", schema, tableName)
+}
+
+// MakeMetaTableExpr makes the model table expression of the meta table in the given schema.
+// NOTE: meta models are aliased by the table name, so that column references are same in every schema
+func MakeMetaTableExpr(schema, tableName string) string {
+	if schema == "" {
+		schema = DefaultMetaSchema
+	}
+	return fmt.Sprintf(`"%s"."%s" AS "%s"`, schema, tableName, tableName)
+}
+
+// MakeCreateMetaPartitionTableQuery makes the query creating the chain partition of the meta table like validator_info in the given schema
+func MakeCreateMetaPartitionTableQuery(schema, tableName, chainID string, chainInfoID int64) string {
 	return fmt.Sprintf(
-		`CREATE TABLE IF NOT EXISTS %s PARTITION OF "public"."%s" FOR VALUES IN ('%d');`,
-		MakePartitionTableName(indexName, chainID), indexName, chainInfoID,
+		`CREATE TABLE IF NOT EXISTS %s_%s PARTITION OF %s FOR VALUES IN ('%d');`,
+		MakeMetaTableName(schema, tableName), helper.ParseToSchemaName(chainID), MakeMetaTableName(schema, tableName), chainInfoID,
 	)
 }
@@ -0,0 +1,32 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMakePartitionTableName(t *testing.T) {
+	testCases := []struct {
+		chainID           string
+		expectedTableName string
+	}{
+		{chainID: "cosmoshub-4", expectedTableName: "public.voteindexer_cosmoshub_4"},
+		{chainID: "Agoric-3", expectedTableName: "public.voteindexer_agoric_3"},
+		{chainID: "althea_258432-1", expectedTableName: "public.voteindexer_althea_258432_1"},
+		{chainID: "Evmos_9001-2.1", expectedTableName: "public.voteindexer_evmos_9001_2_1"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.chainID, func(t *testing.T) {
+			tableName := MakePartitionTableName("voteindexer", tc.chainID)
+			assert.Equal(t, tc.expectedTableName, tableName)
+
+			// the created table and the selected table should be same
+			createQuery := MakeCreatePartitionTableQuery("voteindexer", tc.chainID, 1)
+			assert.True(t, strings.HasPrefix(createQuery, fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s PARTITION OF", tableName)))
+		})
+	}
+}
@@ -1,9 +1,11 @@
 package indexer
 
 import (
+	"strconv"
 	"sync"
 	"time"
 
+	"github.com/cosmostation/cvms/internal/common"
 	"github.com/cosmostation/cvms/internal/common/api"
 	"github.com/cosmostation/cvms/internal/common/function"
 	indexertypes "github.com/cosmostation/cvms/internal/common/indexer/types"
@@ -33,6 +35,10 @@ func (vidx *VoteIndexer) batchSync(lastIndexPointerHeight, newIndexPointerHeight
 		vidx.Debugf("by batch sync limit, end height will change to %d", endHeight)
 	}
 
+	// the batch context carries the trace of these heights for the insert and its exemplar
+	batchCtx, endBatchTrace := vidx.startBatchTrace(startHeight, endHeight)
+	defer endBatchTrace()
+
 	// init channel and waitgroup for go-routine
 	ch := make(chan helper.Result)
 	var wg sync.WaitGroup
@@ -45,7 +51,7 @@ func (vidx *VoteIndexer) batchSync(lastIndexPointerHeight, newIndexPointerHeight
 		// this height is last commit height about start height
 		height := (startHeight - 1)
 
-		blockHeight, blockTimestamp, blockProposerAddress, _, lastCommitBlockHeight, blockSignatures, err := api.GetBlock(vidx.CommonClient, height)
+		blockHeight, blockTimestamp, blockProposerAddress, _, lastCommitBlockHeight, blockSignatures, blockHash, err := api.GetBlockWithHash(vidx.CommonClient, height)
 		if err != nil {
 			return lastIndexPointerHeight, errors.Wrap(err, "failed to call api.GetBlock function")
 		}
@@ -63,6 +69,7 @@ func (vidx *VoteIndexer) batchSync(lastIndexPointerHeight, newIndexPointerHeight
 			LastCommitBlockHeight: lastCommitBlockHeight,
 			BlockSignatures:       blockSignatures,
 			CosmosValidators:      validators,
+			BlockHash:             blockHash,
 		}
 	}
 
@@ -76,7 +83,7 @@ func (vidx *VoteIndexer) batchSync(lastIndexPointerHeight, newIndexPointerHeight
 			defer wg.Done()
 
 			// get current block for collecting last commit signatures
-			blockHeight, blockTimestamp, blockProposerAddress, _, lastCommitBlockHeight, blockSignatures, err := api.GetBlock(vidx.CommonClient, height)
+			blockHeight, blockTimestamp, blockProposerAddress, _, lastCommitBlockHeight, blockSignatures, blockHash, err := api.GetBlockWithHash(vidx.CommonClient, height)
 			if err != nil {
 				vidx.Errorf("failed to call at %d height data, %s", height, err)
 				ch <- helper.Result{Item: nil, Success: false}
@@ -99,6 +106,7 @@ func (vidx *VoteIndexer) batchSync(lastIndexPointerHeight, newIndexPointerHeight
 					LastCommitBlockHeight: lastCommitBlockHeight,
 					BlockSignatures:       blockSignatures,
 					CosmosValidators:      validators,
+					BlockHash:             blockHash,
 				},
 				Success: true,
 			}
@@ -168,6 +176,7 @@ func (vidx *VoteIndexer) batchSync(lastIndexPointerHeight, newIndexPointerHeight
 			vidx.FetchValidatorInfoList()
 			return lastIndexPointerHeight, errors.WithStack(err)
 		}
+		vidx.repo.InvalidateValidatorInfoCache(vidx.ChainID)
 
 		// get already saved tendermint validator list for mapping validators ids
 		validatorInfoList, err := vidx.repo.GetValidatorInfoListByChainInfoID(vidx.ChainInfoID)
@@ -183,6 +192,12 @@ func (vidx *VoteIndexer) batchSync(lastIndexPointerHeight, newIndexPointerHeight
 		vidx.Debugf("changed vim length: %d", len(vidx.Vim))
 	}
 
+	// keep validators' voting power in the validator info table up to date
+	err := vidx.UpdateValidatorVotingPowers(blockSummaryList[endHeight].CosmosValidators)
+	if err != nil {
+		return lastIndexPointerHeight, errors.WithStack(err)
+	}
+
 	ValidatorVoteList := make([]model.ValidatorVote, 0)
 	for height := startHeight; height <= endHeight; height++ {
 		lastCommitHeight := (height - 1)
@@ -192,12 +207,21 @@ func (vidx *VoteIndexer) batchSync(lastIndexPointerHeight, newIndexPointerHeight
 			vidx.Panicln("unexpected errors: not matched block data in block summary list")
 		}
 
+		// NOTE: votes are stored with the block header time, so the time retention works in chain time rather than ingest time
+		err := validateBlockTimestamp(blockSummaryList[lastCommitHeight].BlockTimeStamp, blockSummaryList[height].BlockTimeStamp, vidx.maxTimestampSkew)
+		if err != nil {
+			return lastIndexPointerHeight, errors.Wrapf(err, "invalid block timestamp at %d height", height)
+		}
+
 		tempValidatorVoteList, err := makeValidatorVoteList(
 			// logger
 			vidx.Entry,
 			// vms instance data
 			vidx.ChainInfoID,
 			vidx.Vim,
+			vidx.statusMapping,
+			vidx.trackProposer,
+			vidx.storeRawFlag,
 			// previous block data
 			blockSummaryList[lastCommitHeight].BlockHeight,
 			blockSummaryList[lastCommitHeight].BlockTimeStamp,
@@ -210,6 +234,22 @@ func (vidx *VoteIndexer) batchSync(lastIndexPointerHeight, newIndexPointerHeight
 			return lastIndexPointerHeight, errors.Wrapf(err, "failed to make temp validator miss list at %d height", height)
 		}
 
+		// votes are stored at the last commit height, so that they have the block hash of the last commit block
+		if vidx.storeBlockHash && blockSummaryList[lastCommitHeight].BlockHash != "" {
+			blockHash := blockSummaryList[lastCommitHeight].BlockHash
+			for idx := range tempValidatorVoteList {
+				tempValidatorVoteList[idx].BlockHash = &blockHash
+			}
+		}
+
+		// drop votes of validators which weren't in the active set at the height for keeping miss rate denominators correct
+		// NOTE: the active set comes from the last commit signatures of the current block, not from the validators api which made the votes
+		activeValidatorSet := makeCommitValidatorSet(blockSummaryList[lastCommitHeight].CosmosValidators, blockSummaryList[height].BlockSignatures)
+		tempValidatorVoteList, dropped := filterValidatorVoteListByActiveSet(vidx.Vim, activeValidatorSet, tempValidatorVoteList)
+		if dropped > 0 {
+			vidx.Warnf("dropped %d votes of validators out of the active set at %d height", dropped, lastCommitHeight)
+		}
+
 		ValidatorVoteList = append(ValidatorVoteList, tempValidatorVoteList...)
 	}
 
@@ -241,16 +281,35 @@ func (vidx *VoteIndexer) batchSync(lastIndexPointerHeight, newIndexPointerHeight
 	}
 
 	// need to save list and new pointer
-	err := vidx.repo.InsertValidatorVoteList(vidx.ChainInfoID, blockSummaryList[endHeight].BlockHeight, ValidatorVoteList)
+	insertStartTime := time.Now()
+	inserted, err := vidx.repo.InsertValidatorVoteList(batchCtx, vidx.ChainInfoID, blockSummaryList[endHeight].BlockHeight, ValidatorVoteList)
+	vidx.observeInsertDuration(batchCtx, time.Since(insertStartTime).Seconds())
 	if err != nil {
+		vidx.increaseErrorsMetric(common.InsertPhase)
+		vidx.increaseTxRollbackMetric(err)
 		return lastIndexPointerHeight, errors.Wrapf(err, "failed to insert from %d to %d height", startHeight, endHeight)
 	}
+	vidx.Debugf("inserted %d validator vote rows from %d to %d height", inserted, startHeight, endHeight)
+	vidx.increaseInsertedMetric(ValidatorVoteList)
+	vidx.notifyStatusTransitions(ValidatorVoteList)
 
 	// update metrics
 	vidx.updatePrometheusMetrics(blockSummaryList[endHeight].BlockHeight, blockSummaryList[endHeight].BlockTimeStamp)
 	return blockSummaryList[endHeight].BlockHeight, nil
 }
 
+// validateBlockTimestamp rejects a block time which goes backward from the previous block time,
+// or which is ahead of it more than the max skew. 0 max skew disables the forward check for chains which can halt.
+func validateBlockTimestamp(prevBlockTimestamp, blockTimestamp time.Time, maxSkew time.Duration) error {
+	if blockTimestamp.Before(prevBlockTimestamp) {
+		return errors.Errorf("block time %s is before the previous block time %s", blockTimestamp, prevBlockTimestamp)
+	}
+	if maxSkew > 0 && blockTimestamp.Sub(prevBlockTimestamp) > maxSkew {
+		return errors.Errorf("block time %s is ahead of the previous block time %s over %s", blockTimestamp, prevBlockTimestamp, maxSkew)
+	}
+	return nil
+}
+
 // make validator miss list with current & previous block data
 // return list is will be inserted in the database
 func makeValidatorVoteList(
@@ -258,6 +317,9 @@ func makeValidatorVoteList(
 	vml *logrus.Entry,
 	chainInfoID int64,
 	validatorIDMap indexertypes.ValidatorIDMap,
+	statusMapping StatusMapping,
+	trackProposer bool,
+	storeRawFlag bool,
 	// previous block data
 	lastCommitBlockHeight int64,
 	lastCommitBlockTimestamp time.Time,
@@ -267,63 +329,77 @@ func makeValidatorVoteList(
 	blockSignatures []types.Signature,
 ) ([]model.ValidatorVote, error) {
 	ValidatorVoteList := make([]model.ValidatorVote, 0)
-	lastCommitBlockProposerAddressID, exist := validatorIDMap[lastCommitBlockProposerAddress]
-	if !exist {
-		return nil, errors.New("failed to find block proposer hex address id in validator id maps")
+	// NOTE: 0 never matches any validator id, so that the proposer is stored as committed when it's not tracked
+	var lastCommitBlockProposerAddressID int64
+	if trackProposer {
+		proposerAddressID, exist := validatorIDMap[lastCommitBlockProposerAddress]
+		if !exist {
+			return nil, errors.New("failed to find block proposer hex address id in validator id maps")
+		}
+		lastCommitBlockProposerAddressID = proposerAddressID
 	}
 
 	for idx, validator := range lastCommitValidators {
-		// Note that it used to be block.Block.LastCommit.Precommits[i] == nil
-		if blockSignatures[idx].Signature == nil {
-			vml.Debugf(
-				`found miss validator <idx: %d, address: %s> in this block <height: %d>`,
-				validatorIDMap[validator.Address], validator.Address, lastCommitBlockHeight,
-			)
+		// voting power snapshot of the validator at the last commit height
+		votingPower, err := strconv.ParseInt(validator.VotingPower, 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse voting power of %s", validator.Address)
+		}
 
+		// NOTE: partial block data without the signature of the validator is stored as unknown rather than biasing counts
+		if idx >= len(blockSignatures) {
 			validatorHexAddressID, exist := validatorIDMap[validator.Address]
 			if !exist {
-				return nil, errors.New("failed to find missed validators hex address id in validator id maps")
+				return nil, errors.New("failed to find unknown validators hex address id in validator id maps")
 			}
 
 			ValidatorVoteList = append(ValidatorVoteList, model.ValidatorVote{
-				ChainInfoID: chainInfoID,
-				// current block data
+				ChainInfoID:           chainInfoID,
 				ValidatorHexAddressID: validatorHexAddressID,
-				// previous block data
-				Height:    lastCommitBlockHeight,
-				Timestamp: lastCommitBlockTimestamp,
-				Status:    model.Missed,
+				Height:                lastCommitBlockHeight,
+				Timestamp:             lastCommitBlockTimestamp,
+				Status:                model.VoteStatusUnknown,
+				ValidatorVotePower:    &votingPower,
 			})
-		} else {
-			validatorHexAddressID, exist := validatorIDMap[validator.Address]
-			if !exist {
-				return nil, errors.New("failed to find missed validators hex address id in validator id maps")
-			}
+			continue
+		}
 
-			if lastCommitBlockProposerAddressID == validatorHexAddressID {
-				// for only proposer
-				ValidatorVoteList = append(ValidatorVoteList, model.ValidatorVote{
-					ChainInfoID: chainInfoID,
-					// current block data
-					ValidatorHexAddressID: validatorHexAddressID,
-					// previous block data
-					Height:    lastCommitBlockHeight,
-					Timestamp: lastCommitBlockTimestamp,
-					Status:    model.Proposed,
-				})
-			} else {
-				// for voters, not proposer
-				ValidatorVoteList = append(ValidatorVoteList, model.ValidatorVote{
-					ChainInfoID: chainInfoID,
-					// current block data
-					ValidatorHexAddressID: validatorHexAddressID,
-					// previous block data
-					Height:    lastCommitBlockHeight,
-					Timestamp: lastCommitBlockTimestamp,
-					Status:    model.Voted,
-				})
-			}
+		// raw flag is nil when it isn't stored, and it's inserted as null
+		var rawFlag *int64
+		if storeRawFlag {
+			flag := blockSignatures[idx].BlockIDFlag
+			rawFlag = &flag
 		}
+
+		validatorHexAddressID, exist := validatorIDMap[validator.Address]
+		if !exist {
+			return nil, errors.New("failed to find validators hex address id in validator id maps")
+		}
+
+		// the mapped status is stored as it is, only the proposer's committed signature is overridden into proposed
+		status := statusMapping.Status(blockSignatures[idx])
+		if status == model.VoteStatusCommitted && lastCommitBlockProposerAddressID == validatorHexAddressID {
+			status = model.VoteStatusProposed
+		}
+		if status == model.VoteStatusMissed {
+			vml.Debugf(
+				`found miss validator <idx: %d, address: %s> in this block <height: %d>`,
+				validatorHexAddressID, validator.Address, lastCommitBlockHeight,
+			)
+		}
+
+		ValidatorVoteList = append(ValidatorVoteList, model.ValidatorVote{
+			ChainInfoID: chainInfoID,
+			// current block data
+			ValidatorHexAddressID: validatorHexAddressID,
+			// previous block data
+			Height:    lastCommitBlockHeight,
+			Timestamp: lastCommitBlockTimestamp,
+			Status:    status,
+			RawFlag:   rawFlag,
+			// voting power at the height
+			ValidatorVotePower: &votingPower,
+		})
 	}
 
 	return ValidatorVoteList, nil
@@ -341,3 +417,42 @@ func filterValidatorVoteListByMonikers(monikerIDMap indexertypes.MonikerIDMap, v
 	}
 	return newValidatorVoteList
 }
+
+// makeCommitValidatorSet returns hex addresses of the active validator set which signed the last commit.
+// commit signatures are ordered by the validator set, and a signed slot has the address of its signer,
+// so a validator whose slot is signed by another address isn't in the set, e.g. the validators api answered from another height.
+// absent slots and slots beyond partial signatures don't have addresses, so the validator listed at the slot is kept.
+func makeCommitValidatorSet(lastCommitValidators []types.CosmosValidator, blockSignatures []types.Signature) map[string]bool {
+	activeValidatorSet := make(map[string]bool, len(lastCommitValidators))
+	for idx, validator := range lastCommitValidators {
+		if idx < len(blockSignatures) && blockSignatures[idx].ValidatorAddress != "" {
+			activeValidatorSet[blockSignatures[idx].ValidatorAddress] = true
+			continue
+		}
+		activeValidatorSet[validator.Address] = true
+	}
+	return activeValidatorSet
+}
+
+// filterValidatorVoteListByActiveSet drops votes whose validator isn't in the active validator set of the height,
+// and returns the filtered list with the count of dropped votes
+func filterValidatorVoteListByActiveSet(validatorIDMap indexertypes.ValidatorIDMap, activeValidatorSet map[string]bool, vvList []model.ValidatorVote) (
+	/* filtered list */ []model.ValidatorVote,
+	/* dropped count */ int,
+) {
+	activeIDMap := make(map[int64]bool, len(activeValidatorSet))
+	for address := range activeValidatorSet {
+		validatorHexAddressID, exist := validatorIDMap[address]
+		if exist {
+			activeIDMap[validatorHexAddressID] = true
+		}
+	}
+
+	newValidatorVoteList := make([]model.ValidatorVote, 0, len(vvList))
+	for _, vv := range vvList {
+		if activeIDMap[vv.ValidatorHexAddressID] {
+			newValidatorVoteList = append(newValidatorVoteList, vv)
+		}
+	}
+	return newValidatorVoteList, len(vvList) - len(newValidatorVoteList)
+}
@@ -0,0 +1,67 @@
+package indexer
+
+import (
+	"testing"
+	"time"
+
+	indexertypes "github.com/cosmostation/cvms/internal/common/indexer/types"
+	"github.com/cosmostation/cvms/internal/common/types"
+	"github.com/cosmostation/cvms/internal/packages/consensus/voteindexer/model"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterValidatorVoteListByActiveSet(t *testing.T) {
+	validatorIDMap := indexertypes.ValidatorIDMap{"AAAA": 1, "BBBB": 2, "CCCC": 3, "DDDD": 4}
+
+	// the validators api answered the set of another height, so that CCCC's slot is signed by DDDD
+	lastCommitValidators := []types.CosmosValidator{{Address: "AAAA"}, {Address: "BBBB"}, {Address: "CCCC"}}
+	blockSignatures := []types.Signature{
+		{BlockIDFlag: BlockIDFlagCommit, ValidatorAddress: "AAAA"},
+		{BlockIDFlag: BlockIDFlagAbsent, ValidatorAddress: ""},
+		{BlockIDFlag: BlockIDFlagCommit, ValidatorAddress: "DDDD"},
+	}
+	vvList := []model.ValidatorVote{
+		{ValidatorHexAddressID: 1, Height: 10, Status: model.VoteStatusCommitted},
+		{ValidatorHexAddressID: 2, Height: 10, Status: model.VoteStatusMissed},
+		{ValidatorHexAddressID: 3, Height: 10, Status: model.VoteStatusCommitted},
+	}
+
+	activeValidatorSet := makeCommitValidatorSet(lastCommitValidators, blockSignatures)
+	assert.Equal(t, map[string]bool{"AAAA": true, "BBBB": true, "DDDD": true}, activeValidatorSet)
+
+	filtered, dropped := filterValidatorVoteListByActiveSet(validatorIDMap, activeValidatorSet, vvList)
+	assert.Equal(t, 1, dropped)
+	assert.Equal(t, vvList[:2], filtered)
+}
+
+func TestMakeValidatorVoteListStatusMapping(t *testing.T) {
+	validatorIDMap := indexertypes.ValidatorIDMap{"AAAA": 1, "BBBB": 2, "CCCC": 3}
+	lastCommitValidators := []types.CosmosValidator{
+		{Address: "AAAA", VotingPower: "100"},
+		{Address: "BBBB", VotingPower: "200"},
+		{Address: "CCCC", VotingPower: "300"},
+	}
+	blockSignatures := []types.Signature{
+		{BlockIDFlag: BlockIDFlagCommit, ValidatorAddress: "AAAA", Signature: "sig"},
+		{BlockIDFlag: BlockIDFlagNil, ValidatorAddress: "BBBB", Signature: "sig"},
+		{BlockIDFlag: BlockIDFlagAbsent},
+	}
+
+	// nil votes are mapped into unknown, and the proposer AAAA is overridden into proposed
+	statusMapping := StatusMapping{
+		BlockIDFlagAbsent: model.VoteStatusMissed,
+		BlockIDFlagCommit: model.VoteStatusCommitted,
+		BlockIDFlagNil:    model.VoteStatusUnknown,
+	}
+	vvList, err := makeValidatorVoteList(logrus.NewEntry(logrus.StandardLogger()), 1, validatorIDMap, statusMapping, true, false,
+		10, time.Unix(1703140759, 0), "AAAA", lastCommitValidators, blockSignatures)
+	assert.NoError(t, err)
+
+	statuses := make([]model.VoteStatus, 0, len(vvList))
+	for _, vv := range vvList {
+		statuses = append(statuses, vv.Status)
+	}
+	assert.Equal(t, []model.VoteStatus{model.VoteStatusProposed, model.VoteStatusUnknown, model.VoteStatusMissed}, statuses)
+	assert.Equal(t, int64(300), *vvList[2].ValidatorVotePower)
+}
@@ -0,0 +1,50 @@
+package indexer
+
+import (
+	"context"
+
+	"github.com/cosmostation/cvms/internal/common"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// TraceIDFunc returns the trace id of the context, empty string means no trace
+type TraceIDFunc func(ctx context.Context) string
+
+// BatchTraceFunc starts a trace for the batch of heights and returns the context carrying it with the func ending it
+type BatchTraceFunc func(ctx context.Context, chainID string, startHeight, endHeight int64) (context.Context, func())
+
+// SetTraceIDFunc attaches the trace id as an OpenMetrics exemplar to the insert duration histogram,
+// so that a slow block insert links to its trace
+func (vidx *VoteIndexer) SetTraceIDFunc(fn TraceIDFunc) *VoteIndexer {
+	vidx.traceIDFunc = fn
+	return vidx
+}
+
+// SetBatchTraceFunc starts a trace per batch sync, the trace id of the batch context is used for exemplars.
+// NOTE: the root context of the indexer never carries a trace, so exemplars are only attached with this func
+func (vidx *VoteIndexer) SetBatchTraceFunc(fn BatchTraceFunc) *VoteIndexer {
+	vidx.batchTraceFunc = fn
+	return vidx
+}
+
+// startBatchTrace returns the batch context for the heights, it's the root context when no batch trace func is set
+func (vidx *VoteIndexer) startBatchTrace(startHeight, endHeight int64) (context.Context, func()) {
+	if vidx.batchTraceFunc == nil {
+		return vidx.Ctx, func() {}
+	}
+	return vidx.batchTraceFunc(vidx.Ctx, vidx.ChainID, startHeight, endHeight)
+}
+
+// observeInsertDuration observes with an exemplar when a trace is present, otherwise it's a plain observe
+func (vidx *VoteIndexer) observeInsertDuration(ctx context.Context, seconds float64) {
+	histogram := vidx.HistogramMap[common.InsertDurationMetricName]
+	if vidx.traceIDFunc != nil {
+		if traceID := vidx.traceIDFunc(ctx); traceID != "" {
+			if eo, ok := histogram.(prometheus.ExemplarObserver); ok {
+				eo.ObserveWithExemplar(seconds, prometheus.Labels{common.TraceIDLabel: traceID})
+				return
+			}
+		}
+	}
+	histogram.Observe(seconds)
+}
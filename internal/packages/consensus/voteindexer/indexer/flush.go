@@ -0,0 +1,49 @@
+package indexer
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// batchGate is held during each batch sync, and closing it stops the loop before the next batch
+type batchGate struct {
+	mu     sync.Mutex
+	closed bool
+}
+
+// enter holds the gate for a batch sync, false means the gate was closed by flush
+func (g *batchGate) enter() bool {
+	g.mu.Lock()
+	if g.closed {
+		g.mu.Unlock()
+		return false
+	}
+	return true
+}
+
+func (g *batchGate) leave() {
+	g.mu.Unlock()
+}
+
+// Flush waits for the in-flight batch sync and stops the loop before the next batch, so that shutdown doesn't abort it.
+// NOTE: votes aren't buffered in memory, each batch is inserted together with advancing the index pointer in one transaction.
+// so there is nothing more to write, and it returns immediately when no batch is in flight. it's safe to call multiple times
+func (vidx *VoteIndexer) Flush(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		vidx.gate.mu.Lock()
+		vidx.gate.closed = true
+		vidx.gate.mu.Unlock()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		vidx.Infoln("flushed the in-flight batch and stopped the indexer loop")
+		return nil
+	case <-ctx.Done():
+		return errors.Wrapf(ctx.Err(), "failed to wait for the in-flight batch of %s", vidx.ChainID)
+	}
+}
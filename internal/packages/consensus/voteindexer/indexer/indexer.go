@@ -2,9 +2,11 @@ package indexer
 
 import (
 	"database/sql"
+	"strconv"
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/cosmostation/cvms/internal/helper"
 	"github.com/cosmostation/cvms/internal/helper/db"
@@ -12,6 +14,8 @@ import (
 
 	"github.com/cosmostation/cvms/internal/common"
 	indexertypes "github.com/cosmostation/cvms/internal/common/indexer/types"
+	"github.com/cosmostation/cvms/internal/common/types"
+	"github.com/cosmostation/cvms/internal/packages/consensus/voteindexer/model"
 	"github.com/cosmostation/cvms/internal/packages/consensus/voteindexer/repository"
 )
 
@@ -22,7 +26,30 @@ var (
 
 type VoteIndexer struct {
 	*common.Indexer
-	repo repository.VoteIndexerRepository
+	repo repository.IVoteIndexerRepository
+	// last saved voting power map by hex address
+	vpm map[string]int64
+	// monikers reported in recent vote metrics, to reset absent validators into zero
+	rvm map[string]bool
+	// block id flags into vote statuses for this chain
+	statusMapping StatusMapping
+	// optional callback for status transitions and the last statuses by validator hex address id for detecting them
+	onStatusTransition StatusTransitionFunc
+	lsm                map[int64]model.VoteStatus
+	// max forward skew of a block time from the previous block time, 0 means disabled
+	maxTimestampSkew time.Duration
+	// store the proposer's vote as proposed, true by default
+	trackProposer bool
+	// optional trace id extractor for exemplars of the insert duration histogram
+	traceIDFunc TraceIDFunc
+	// optional trace starter per batch sync, the root context is used for batches without it
+	batchTraceFunc BatchTraceFunc
+	// store raw block id flags of signatures for auditing the status mapping, false by default
+	storeRawFlag bool
+	// store block hashes of heights for detecting reorgs, true by default
+	storeBlockHash bool
+	// held during each batch sync for flushing on shutdown
+	gate *batchGate
 }
 
 // Compile-time Assertion
@@ -34,9 +61,53 @@ func NewVoteIndexer(p common.Packager) (*VoteIndexer, error) {
 		return nil, errors.Errorf("failed to create new voteindexer by failing getting onchain status through %v", p.RPCs)
 	}
 	indexer := common.NewIndexer(p, p.Package, status.ChainID)
-	repo := repository.NewRepository(*p.IndexerDB, indexertypes.SQLQueryMaxDuration)
+	repo := repository.NewRepository(*p.IndexerDB, indexertypes.SQLQueryMaxDuration, indexer.Entry)
+	repo = repo.WithHeightPartitionInterval(p.IndexerDB.HeightPartitionInterval)
 	indexer.Lh = indexertypes.LatestHeightCache{LatestHeight: status.BlockHeight}
-	return &VoteIndexer{indexer, repo}, nil
+	return &VoteIndexer{
+		Indexer:        indexer,
+		repo:           &repo,
+		vpm:            make(map[string]int64),
+		rvm:            make(map[string]bool),
+		statusMapping:  DefaultStatusMapping,
+		lsm:            make(map[int64]model.VoteStatus),
+		trackProposer:  true,
+		storeBlockHash: true,
+		gate:           &batchGate{},
+	}, nil
+}
+
+// SetMaxTimestampSkew rejects blocks whose time is ahead of the previous block time more than the skew
+func (vidx *VoteIndexer) SetMaxTimestampSkew(skew time.Duration) *VoteIndexer {
+	vidx.maxTimestampSkew = skew
+	return vidx
+}
+
+// SetTrackProposer opts out of the proposer status, the proposer's vote is stored as committed when it's false.
+// NOTE: every validator still has one row per height, so the row count and table size don't change.
+// it only skips the proposer lookup per block, and proposer based queries like proposal counts and proposer gaps
+// return nothing for the chain.
+func (vidx *VoteIndexer) SetTrackProposer(trackProposer bool) *VoteIndexer {
+	vidx.trackProposer = trackProposer
+	return vidx
+}
+
+// SetStoreRawFlag stores the raw block id flag with each vote, rows stored before it keep null
+func (vidx *VoteIndexer) SetStoreRawFlag(storeRawFlag bool) *VoteIndexer {
+	vidx.storeRawFlag = storeRawFlag
+	return vidx
+}
+
+// SetStoreBlockHash opts out of storing block hashes, which are repeated in every vote row of the height
+func (vidx *VoteIndexer) SetStoreBlockHash(storeBlockHash bool) *VoteIndexer {
+	vidx.storeBlockHash = storeBlockHash
+	return vidx
+}
+
+// SetStatusMapping overrides the default status mapping for chains which emit different block id flags
+func (vidx *VoteIndexer) SetStatusMapping(statusMapping StatusMapping) *VoteIndexer {
+	vidx.statusMapping = statusMapping
+	return vidx
 }
 
 func (vidx *VoteIndexer) Start() error {
@@ -46,13 +117,26 @@ func (vidx *VoteIndexer) Start() error {
 			return errors.Wrap(err, "failed to init chain_info_id")
 		}
 
-		alreadyInit, err := vidx.repo.CheckIndexpoinerAlreadyInitialized(repository.IndexName, vidx.ChainInfoID)
+		alreadyInit, err := vidx.repo.CheckIndexpoinerAlreadyInitialized(vidx.repo.IndexName(), vidx.ChainInfoID)
 		if err != nil {
 			return errors.Wrap(err, "failed to check init tables")
 		}
 		if !alreadyInit {
 			vidx.Warnln("it's not initialized in the database, so that voteindexer will init for this package")
-			vidx.repo.InitPartitionTablesByChainInfoID(repository.IndexName, vidx.ChainID, vidx.Lh.LatestHeight)
+			// NOTE: the chain partition should be created with height sub-partitions before the meta repository creates it as a leaf table
+			if vidx.repo.HeightPartitionInterval() > 0 {
+				err = vidx.repo.CreateHeightPartitionedTable(vidx.Ctx, vidx.ChainID, vidx.ChainInfoID)
+				if err != nil {
+					return errors.Wrap(err, "failed to create height partitioned table")
+				}
+			}
+			vidx.repo.InitPartitionTablesByChainInfoID(vidx.repo.IndexName(), vidx.ChainID, vidx.Lh.LatestHeight)
+		}
+
+		// make sure the pointer row exists, otherwise the pointer update in the insert path silently matches zero rows
+		err = vidx.repo.EnsureIndexPointer(vidx.Ctx, vidx.ChainInfoID, vidx.Lh.LatestHeight)
+		if err != nil {
+			return errors.Wrap(err, "failed to ensure index pointer")
 		}
 
 		// NOTE:  ...
@@ -60,10 +144,10 @@ func (vidx *VoteIndexer) Start() error {
 		cnt := 0
 	retryLoop:
 		// get last index pointer, index pointer is always initalize if not exist
-		initIndexPointer, err := vidx.repo.GetLastIndexPointerByIndexTableName(repository.IndexName, vidx.ChainInfoID)
+		initIndexPointer, err := vidx.repo.GetLastIndexPointerByIndexTableName(vidx.repo.IndexName(), vidx.ChainInfoID)
 		if err != nil {
 			if cnt < maxBackOffCnt {
-				vidx.repo.InitPartitionTablesByChainInfoID(repository.IndexName, vidx.ChainID, vidx.Lh.LatestHeight)
+				vidx.repo.InitPartitionTablesByChainInfoID(vidx.repo.IndexName(), vidx.ChainID, vidx.Lh.LatestHeight)
 				cnt++
 				vidx.Warnln("found unexpected init index pointer and so retry until max 5 times")
 				goto retryLoop
@@ -71,6 +155,40 @@ func (vidx *VoteIndexer) Start() error {
 			return errors.Wrap(err, "failed to get last index pointer")
 		}
 
+		// fail fast when the partition table or meta schema isn't ready
+		err = vidx.repo.HealthCheck(vidx.Ctx, vidx.ChainID)
+		if err != nil {
+			return errors.Wrap(err, "failed to check voteindexer tables")
+		}
+
+		// make sure the voting power snapshot column exists for power-weighted queries
+		err = vidx.repo.EnsureVotePowerColumn(vidx.Ctx)
+		if err != nil {
+			return errors.Wrap(err, "failed to ensure vote power column")
+		}
+
+		// the raw flag column is always inserted, even when the raw flags aren't stored
+		err = vidx.repo.EnsureRawFlagColumn(vidx.Ctx)
+		if err != nil {
+			return errors.Wrap(err, "failed to ensure raw flag column")
+		}
+
+		// the block hash column is always inserted for the same reason
+		err = vidx.repo.EnsureBlockHashColumn(vidx.Ctx)
+		if err != nil {
+			return errors.Wrap(err, "failed to ensure block hash column")
+		}
+
+		// fail fast when another process already indexes this chain
+		release, err := vidx.repo.AcquireChainLock(vidx.Ctx, vidx.ChainInfoID)
+		if err != nil {
+			return errors.Wrap(err, "failed to acquire the chain lock")
+		}
+		go func() {
+			<-vidx.Ctx.Done()
+			release()
+		}()
+
 		err = vidx.FetchValidatorInfoList()
 		if err != nil {
 			return errors.Wrap(err, "failed to fetch validator_info list")
@@ -81,6 +199,8 @@ func (vidx *VoteIndexer) Start() error {
 
 		// init indexer metrics
 		vidx.initLabelsAndMetrics()
+		// wait for the in-flight batch on shutdown
+		common.RegisterFlusher(vidx)
 		// go fetch new height in loop, it must be after init metrics
 		go vidx.FetchLatestHeight()
 		// loop
@@ -90,18 +210,53 @@ func (vidx *VoteIndexer) Start() error {
 			for {
 				vidx.Infoln("update recent miss counter metrics and sleep 5s sec...")
 				vidx.updateRecentMissCounterMetric()
+				vidx.updateIndexPointerLagMetric()
+				vidx.updateIndexCompletenessMetric()
+				vidx.updateSecondsSinceLastBlockMetric()
 				time.Sleep(time.Second * 5)
 			}
 		}()
+		// loop update proposer metrics over long windows in a slower interval
+		go func() {
+			for {
+				vidx.updateProposerGapMetric()
+				vidx.updateProposalDeficitMetric()
+				time.Sleep(indexertypes.ProposerMetricsSleepDuration)
+			}
+		}()
+		// loop update partition rows metric in a slow interval
+		go func() {
+			for {
+				vidx.updatePartitionRowsMetric()
+				time.Sleep(indexertypes.PartitionRowCountSleepDuration)
+			}
+		}()
 		// loop partion table time retention by env parameter
 		go func() {
 			if vidx.RetentionPeriod == db.PersistenceMode {
 				vidx.Infoln("skipped the postgres time retention")
 				return
 			}
+			// make sure retention deletes don't scan the whole partition
+			err := vidx.repo.EnsureRetentionIndex(vidx.Ctx, vidx.ChainID)
+			if err != nil {
+				vidx.Errorf("failed to ensure retention index: %s", err)
+			}
 			for {
 				vidx.Infof("for time retention, delete old records over %s and sleep %s", vidx.RetentionPeriod, indexertypes.RetentionQuerySleepDuration)
-				vidx.repo.DeleteOldValidatorVoteList(vidx.ChainID, vidx.RetentionPeriod)
+				// expired height sub-partitions are dropped at once, and the remaining old rows are deleted.
+				// deleted rows include both of them for the retention metric
+				retentionStartTime := time.Now()
+				droppedTables, deletedRows, err := vidx.repo.DropExpiredPartitions(vidx.Ctx, vidx.ChainID, vidx.RetentionPeriod)
+				vidx.HistogramMap[common.RetentionDurationMetricName].Observe(time.Since(retentionStartTime).Seconds())
+				vidx.CounterMap[common.RetentionDeletedTotalMetricName].Add(float64(deletedRows))
+				if err != nil {
+					vidx.Errorf("failed to delete old records: %s", err)
+					vidx.increaseErrorsMetric(common.DeletePhase)
+				}
+				if len(droppedTables) > 0 {
+					vidx.Infof("dropped expired partitions: %v", droppedTables)
+				}
 				time.Sleep(indexertypes.RetentionQuerySleepDuration)
 			}
 		}()
@@ -114,6 +269,12 @@ func (vidx *VoteIndexer) Start() error {
 func (vidx *VoteIndexer) Loop(indexPoint int64) {
 	isUnhealth := false
 	for {
+		// stop the loop when the root context was canceled by shutdown
+		if vidx.Ctx.Err() != nil {
+			vidx.Infoln("the root context was canceled, so that the indexer loop will be stopped")
+			return
+		}
+
 		// node health check
 		if isUnhealth {
 			healthAPIs := healthcheck.FilterHealthEndpoints(vidx.APIs, vidx.ProtocolType)
@@ -143,8 +304,13 @@ func (vidx *VoteIndexer) Loop(indexPoint int64) {
 		// set new index point height
 		newIndexPointerHeight := indexPoint + 1
 
-		// trying to sync with new index pointer height
+		// trying to sync with new index pointer height, the loop is stopped when it was flushed on shutdown
+		if !vidx.gate.enter() {
+			vidx.Infoln("the indexer was flushed, so that the indexer loop will be stopped")
+			return
+		}
 		newIndexPointer, err := vidx.batchSync(indexPoint, newIndexPointerHeight)
+		vidx.gate.leave()
 		if err != nil {
 			common.Health.With(vidx.RootLabels).Set(0)
 			common.Ops.With(vidx.RootLabels).Inc()
@@ -216,3 +382,57 @@ func (vidx *VoteIndexer) FetchValidatorInfoList() error {
 
 	return nil
 }
+
+func (vidx *VoteIndexer) UpdateValidatorVotingPowers(validators []types.CosmosValidator) error {
+	// collect only changed voting powers to avoid needless updates
+	votingPowers := make(map[string]int64)
+	activeValidators := make(map[string]bool, len(validators))
+	for _, validator := range validators {
+		votingPower, err := strconv.ParseInt(validator.VotingPower, 10, 64)
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse voting power of %s", validator.Address)
+		}
+		activeValidators[validator.Address] = true
+		if prev, exist := vidx.vpm[validator.Address]; exist && prev == votingPower {
+			continue
+		}
+		votingPowers[validator.Address] = votingPower
+	}
+
+	// validators out of the active set don't have any voting power
+	for hexAddress, prev := range vidx.vpm {
+		if !activeValidators[hexAddress] && prev != 0 {
+			votingPowers[hexAddress] = 0
+		}
+	}
+
+	if len(votingPowers) == 0 {
+		return nil
+	}
+
+	err := vidx.repo.UpdateValidatorInfoVotingPowers(vidx.ChainInfoID, votingPowers)
+	if err != nil {
+		return errors.Wrap(err, "failed to update validator voting powers")
+	}
+
+	for hexAddress, votingPower := range votingPowers {
+		vidx.vpm[hexAddress] = votingPower
+	}
+
+	vidx.Debugf("updated %d validators' voting power", len(votingPowers))
+	return nil
+}
+
+// RecordEvidence stores a slashable evidence of the validator and increases the evidence metric
+func (vidx *VoteIndexer) RecordEvidence(height int64, validatorHexAddressID int64, moniker, evidenceType string) error {
+	err := vidx.repo.InsertEvidence(vidx.Ctx, vidx.ChainID, height, validatorHexAddressID, evidenceType)
+	if err != nil {
+		vidx.increaseErrorsMetric(common.InsertPhase)
+		return errors.Wrap(err, "failed to record evidence")
+	}
+
+	vidx.CounterVecMap[common.ValidatorEvidenceTotalMetricName].
+		With(prometheus.Labels{common.MonikerLabel: moniker, common.EvidenceTypeLabel: evidenceType}).
+		Inc()
+	return nil
+}
@@ -4,9 +4,15 @@ import (
 	"time"
 
 	"github.com/cosmostation/cvms/internal/common"
+	"github.com/cosmostation/cvms/internal/packages/consensus/voteindexer/model"
+	"github.com/cosmostation/cvms/internal/packages/consensus/voteindexer/repository"
+	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+// EmptyPartitionSentinel is reported as the seconds since last block until the partition has any rows
+const EmptyPartitionSentinel = -1
+
 func (vidx *VoteIndexer) initLabelsAndMetrics() {
 	indexPointerBlockHeightMetric := vidx.Factory.NewGauge(prometheus.GaugeOpts{
 		Namespace:   common.Namespace,
@@ -35,6 +41,139 @@ func (vidx *VoteIndexer) initLabelsAndMetrics() {
 		common.MonikerLabel,
 	})
 
+	recentVoteMetrics := make(map[string]*prometheus.GaugeVec)
+	for _, name := range []string{
+		common.RecentCommittedMetricName,
+		common.RecentProposedMetricName,
+		common.RecentMissedMetricName,
+		common.RecentMissedPowerMetricName,
+	} {
+		recentVoteMetrics[name] = vidx.Factory.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace:   common.Namespace,
+			Subsystem:   subsystem,
+			Name:        name,
+			ConstLabels: vidx.PackageLabels,
+		}, []string{
+			common.MonikerLabel,
+		})
+	}
+
+	blocksSinceLastProposalMetric := vidx.Factory.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace:   common.Namespace,
+		Subsystem:   subsystem,
+		Name:        common.BlocksSinceLastProposalMetricName,
+		ConstLabels: vidx.PackageLabels,
+	}, []string{
+		common.MonikerLabel,
+	})
+
+	proposalDeficitRatioMetric := vidx.Factory.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace:   common.Namespace,
+		Subsystem:   subsystem,
+		Name:        common.ProposalDeficitRatioMetricName,
+		ConstLabels: vidx.PackageLabels,
+	}, []string{
+		common.MonikerLabel,
+	})
+
+	indexPointerLagMetric := vidx.Factory.NewGauge(prometheus.GaugeOpts{
+		Namespace:   common.Namespace,
+		Subsystem:   subsystem,
+		Name:        common.IndexPointerLagMetricName,
+		ConstLabels: vidx.PackageLabels,
+	})
+
+	indexCompletenessMetric := vidx.Factory.NewGauge(prometheus.GaugeOpts{
+		Namespace:   common.Namespace,
+		Subsystem:   subsystem,
+		Name:        common.IndexCompletenessMetricName,
+		ConstLabels: vidx.PackageLabels,
+	})
+
+	secondsSinceLastBlockMetric := vidx.Factory.NewGauge(prometheus.GaugeOpts{
+		Namespace:   common.Namespace,
+		Subsystem:   subsystem,
+		Name:        common.SecondsSinceLastBlockMetricName,
+		ConstLabels: vidx.PackageLabels,
+	})
+
+	partitionRowsMetric := vidx.Factory.NewGauge(prometheus.GaugeOpts{
+		Namespace:   common.Namespace,
+		Subsystem:   subsystem,
+		Name:        common.PartitionRowsMetricName,
+		ConstLabels: vidx.PackageLabels,
+	})
+
+	lastSuccessTimestampMetric := vidx.Factory.NewGauge(prometheus.GaugeOpts{
+		Namespace:   common.Namespace,
+		Subsystem:   subsystem,
+		Name:        common.LastSuccessTimestampMetricName,
+		ConstLabels: vidx.PackageLabels,
+	})
+
+	errorsTotalMetric := vidx.Factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace:   common.Namespace,
+		Subsystem:   subsystem,
+		Name:        common.ErrorsTotalMetricName,
+		ConstLabels: vidx.PackageLabels,
+	}, []string{
+		common.PhaseLabel,
+	})
+
+	insertedTotalMetric := vidx.Factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace:   common.Namespace,
+		Subsystem:   subsystem,
+		Name:        common.InsertedTotalMetricName,
+		ConstLabels: vidx.PackageLabels,
+	}, []string{
+		common.StatusLabel,
+	})
+
+	txRollbackTotalMetric := vidx.Factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace:   common.Namespace,
+		Subsystem:   subsystem,
+		Name:        common.TxRollbackTotalMetricName,
+		ConstLabels: vidx.PackageLabels,
+	}, []string{
+		common.ReasonLabel,
+	})
+
+	validatorEvidenceTotalMetric := vidx.Factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace:   common.Namespace,
+		Subsystem:   subsystem,
+		Name:        common.ValidatorEvidenceTotalMetricName,
+		ConstLabels: vidx.PackageLabels,
+	}, []string{
+		common.MonikerLabel,
+		common.EvidenceTypeLabel,
+	})
+
+	// NOTE: rows of dropped sub-partitions are counted by their estimates, because DROP doesn't report them
+	retentionDeletedTotalMetric := vidx.Factory.NewCounter(prometheus.CounterOpts{
+		Namespace:   common.Namespace,
+		Subsystem:   subsystem,
+		Name:        common.RetentionDeletedTotalMetricName,
+		ConstLabels: vidx.PackageLabels,
+	})
+
+	// 100ms ~ 27m buckets for the chunked retention deletes
+	retentionDurationMetric := vidx.Factory.NewHistogram(prometheus.HistogramOpts{
+		Namespace:   common.Namespace,
+		Subsystem:   subsystem,
+		Name:        common.RetentionDurationMetricName,
+		ConstLabels: vidx.PackageLabels,
+		Buckets:     prometheus.ExponentialBuckets(0.1, 2, 15),
+	})
+
+	// 1ms ~ 4s buckets for db write latency
+	insertDurationMetric := vidx.Factory.NewHistogram(prometheus.HistogramOpts{
+		Namespace:   common.Namespace,
+		Subsystem:   subsystem,
+		Name:        common.InsertDurationMetricName,
+		ConstLabels: vidx.PackageLabels,
+		Buckets:     prometheus.ExponentialBuckets(0.001, 2, 13),
+	})
+
 	indexPointerBlockHeightMetric.Set(0)
 	vidx.MetricsMap[common.IndexPointerBlockHeightMetricName] = indexPointerBlockHeightMetric
 
@@ -44,13 +183,56 @@ func (vidx *VoteIndexer) initLabelsAndMetrics() {
 	latestBlockHeightMetric.Set(0)
 	vidx.MetricsMap[common.LatestBlockHeightMetricName] = latestBlockHeightMetric
 
+	indexPointerLagMetric.Set(0)
+	vidx.MetricsMap[common.IndexPointerLagMetricName] = indexPointerLagMetric
+
 	vidx.MetricsVecMap[common.RecentMissCounterMetricName] = recentMissCounterMetric
+	for name, metric := range recentVoteMetrics {
+		vidx.MetricsVecMap[name] = metric
+	}
+	vidx.MetricsVecMap[common.BlocksSinceLastProposalMetricName] = blocksSinceLastProposalMetric
+	vidx.MetricsVecMap[common.ProposalDeficitRatioMetricName] = proposalDeficitRatioMetric
+
+	indexCompletenessMetric.Set(1)
+	vidx.MetricsMap[common.IndexCompletenessMetricName] = indexCompletenessMetric
+
+	secondsSinceLastBlockMetric.Set(EmptyPartitionSentinel)
+	vidx.MetricsMap[common.SecondsSinceLastBlockMetricName] = secondsSinceLastBlockMetric
+
+	partitionRowsMetric.Set(0)
+	vidx.MetricsMap[common.PartitionRowsMetricName] = partitionRowsMetric
+
+	lastSuccessTimestampMetric.Set(0)
+	vidx.MetricsMap[common.LastSuccessTimestampMetricName] = lastSuccessTimestampMetric
+
+	vidx.HistogramMap[common.InsertDurationMetricName] = insertDurationMetric
+	vidx.HistogramMap[common.RetentionDurationMetricName] = retentionDurationMetric
+	vidx.CounterMap[common.RetentionDeletedTotalMetricName] = retentionDeletedTotalMetric
+
+	// init phases for avoiding absent series before the first error
+	for _, phase := range []string{common.InsertPhase, common.SelectPhase, common.DeletePhase} {
+		errorsTotalMetric.With(prometheus.Labels{common.PhaseLabel: phase}).Add(0)
+	}
+	vidx.CounterVecMap[common.ErrorsTotalMetricName] = errorsTotalMetric
+
+	for _, status := range []model.VoteStatus{model.VoteStatusUnknown, model.VoteStatusMissed, model.VoteStatusCommitted, model.VoteStatusProposed} {
+		insertedTotalMetric.With(prometheus.Labels{common.StatusLabel: status.String()}).Add(0)
+	}
+	vidx.CounterVecMap[common.InsertedTotalMetricName] = insertedTotalMetric
+
+	for _, reason := range repository.RollbackReasons {
+		txRollbackTotalMetric.With(prometheus.Labels{common.ReasonLabel: reason}).Add(0)
+	}
+	vidx.CounterVecMap[common.TxRollbackTotalMetricName] = txRollbackTotalMetric
+	vidx.CounterVecMap[common.ValidatorEvidenceTotalMetricName] = validatorEvidenceTotalMetric
 }
 
 func (vidx *VoteIndexer) updateRecentMissCounterMetric() {
-	rvvList, err := vidx.repo.SelectRecentMissValidatorVoteList(vidx.ChainID)
+	rvvList, err := vidx.repo.SelectRecentMissValidatorVoteList(vidx.Ctx, vidx.ChainID, repository.DefaultRecentWindow)
 	if err != nil {
 		vidx.Errorf("failed to update recent miss counter metric: %s", err)
+		vidx.increaseErrorsMetric(common.SelectPhase)
+		return
 	}
 
 	for _, rvv := range rvvList {
@@ -58,10 +240,176 @@ func (vidx *VoteIndexer) updateRecentMissCounterMetric() {
 			With(prometheus.Labels{common.MonikerLabel: rvv.Moniker}).
 			Set(float64(rvv.MissedCount))
 	}
+
+	vidx.updateRecentVoteMetrics(rvvList)
+}
+
+// updateRecentVoteMetrics sets committed, proposed and missed gauges per validator.
+// validators which were reported before but absent in the window are set into zero for avoiding gaps in the series
+func (vidx *VoteIndexer) updateRecentVoteMetrics(rvvList []model.RecentValidatorVote) {
+	activeMonikers := make(map[string]bool, len(rvvList))
+	for _, rvv := range rvvList {
+		labels := prometheus.Labels{common.MonikerLabel: rvv.Moniker}
+		vidx.MetricsVecMap[common.RecentCommittedMetricName].With(labels).Set(float64(rvv.CommitedCount))
+		vidx.MetricsVecMap[common.RecentProposedMetricName].With(labels).Set(float64(rvv.ProposedCount))
+		vidx.MetricsVecMap[common.RecentMissedMetricName].With(labels).Set(float64(rvv.MissedCount))
+		// power-weighted misses for prioritizing large validators which threaten the 2/3 threshold
+		vidx.MetricsVecMap[common.RecentMissedPowerMetricName].With(labels).Set(float64(rvv.MissedPower))
+		activeMonikers[rvv.Moniker] = true
+		vidx.rvm[rvv.Moniker] = true
+	}
+
+	for moniker := range vidx.rvm {
+		if activeMonikers[moniker] {
+			continue
+		}
+		labels := prometheus.Labels{common.MonikerLabel: moniker}
+		vidx.MetricsVecMap[common.RecentCommittedMetricName].With(labels).Set(0)
+		vidx.MetricsVecMap[common.RecentProposedMetricName].With(labels).Set(0)
+		vidx.MetricsVecMap[common.RecentMissedMetricName].With(labels).Set(0)
+		vidx.MetricsVecMap[common.RecentMissedPowerMetricName].With(labels).Set(0)
+	}
+}
+
+// updateProposerGapMetric catches broken proposing which miss-rate metrics overlook when the validator still precommits
+func (vidx *VoteIndexer) updateProposerGapMetric() {
+	pgList, err := vidx.repo.SelectConsecutiveProposerGaps(vidx.Ctx, vidx.ChainID, repository.DefaultProposerGapWindow)
+	if err != nil {
+		vidx.Errorf("failed to update blocks since last proposal metric: %s", err)
+		vidx.increaseErrorsMetric(common.SelectPhase)
+		return
+	}
+
+	for _, pg := range pgList {
+		vidx.MetricsVecMap[common.BlocksSinceLastProposalMetricName].
+			With(prometheus.Labels{common.MonikerLabel: pg.Moniker}).
+			Set(float64(pg.BlocksSinceLastProposal))
+	}
+}
+
+func (vidx *VoteIndexer) updateIndexPointerLagMetric() {
+	indexPointer, err := vidx.repo.SelectIndexPointer(vidx.Ctx, vidx.ChainInfoID)
+	if err != nil {
+		vidx.Errorf("failed to update index pointer lag metric: %s", err)
+		vidx.increaseErrorsMetric(common.SelectPhase)
+		return
+	}
+
+	vidx.MetricsMap[common.IndexPointerLagMetricName].Set(float64(vidx.Lh.LatestHeight - indexPointer))
+}
+
+// updateProposalDeficitMetric sets the shortfall of actual proposals from expected proposals by the voting power fraction.
+// 0 means the validator proposed as expected or more, and near 1 means it rarely gets proposer turns despite its stake.
+// NOTE: the window should be long enough for the expected proposals of small validators, so that it uses DefaultProposalDeficitWindow
+func (vidx *VoteIndexer) updateProposalDeficitMetric() {
+	pcList, err := vidx.repo.SelectRecentProposalCounts(vidx.Ctx, vidx.ChainID, repository.DefaultProposalDeficitWindow)
+	if err != nil {
+		vidx.Errorf("failed to update proposal deficit ratio metric: %s", err)
+		vidx.increaseErrorsMetric(common.SelectPhase)
+		return
+	}
+
+	var totalPower int64
+	for _, pc := range pcList {
+		totalPower += pc.VotingPower
+	}
+	if totalPower == 0 {
+		return
+	}
+
+	for _, pc := range pcList {
+		expected := float64(pc.VotingPower) / float64(totalPower) * float64(pc.Blocks)
+		if expected == 0 {
+			continue
+		}
+		vidx.MetricsVecMap[common.ProposalDeficitRatioMetricName].
+			With(prometheus.Labels{common.MonikerLabel: pc.Moniker}).
+			Set(max(0, 1-float64(pc.Proposed)/expected))
+	}
+}
+
+// updateIndexCompletenessMetric sets stored heights ratio over the recent window, less than 1 means missing heights
+func (vidx *VoteIndexer) updateIndexCompletenessMetric() {
+	minHeight, maxHeight, err := vidx.repo.SelectIndexedHeightRange(vidx.Ctx, vidx.ChainID)
+	if err != nil {
+		vidx.Errorf("failed to update index completeness metric: %s", err)
+		vidx.increaseErrorsMetric(common.SelectPhase)
+		return
+	}
+	if maxHeight == 0 {
+		return
+	}
+
+	fromHeight := max(minHeight, maxHeight-repository.DefaultRecentWindow+1)
+	expected, actual, err := vidx.repo.SelectIndexCompleteness(vidx.Ctx, vidx.ChainID, fromHeight, maxHeight)
+	if err != nil {
+		vidx.Errorf("failed to update index completeness metric: %s", err)
+		vidx.increaseErrorsMetric(common.SelectPhase)
+		return
+	}
+
+	vidx.MetricsMap[common.IndexCompletenessMetricName].Set(float64(actual) / float64(expected))
+}
+
+// updateSecondsSinceLastBlockMetric sets the wall-clock staleness of the latest indexed block time.
+// it keeps growing while the chain halts, even when the lag by height is ambiguous
+func (vidx *VoteIndexer) updateSecondsSinceLastBlockMetric() {
+	lastTimestamp, err := vidx.repo.SelectLastIndexedTimestamp(vidx.Ctx, vidx.ChainID)
+	if err != nil {
+		vidx.Errorf("failed to update seconds since last block metric: %s", err)
+		vidx.increaseErrorsMetric(common.SelectPhase)
+		return
+	}
+	if lastTimestamp.IsZero() {
+		vidx.MetricsMap[common.SecondsSinceLastBlockMetricName].Set(EmptyPartitionSentinel)
+		return
+	}
+
+	vidx.MetricsMap[common.SecondsSinceLastBlockMetricName].Set(time.Since(lastTimestamp).Seconds())
+}
+
+// updatePartitionRowsMetric sets the row count of the chain partition for monitoring the storage growth
+func (vidx *VoteIndexer) updatePartitionRowsMetric() {
+	rows, err := vidx.repo.SelectPartitionRowCount(vidx.Ctx, vidx.ChainID)
+	if err != nil {
+		vidx.Errorf("failed to update partition rows metric: %s", err)
+		vidx.increaseErrorsMetric(common.SelectPhase)
+		return
+	}
+
+	vidx.MetricsMap[common.PartitionRowsMetricName].Set(float64(rows))
+}
+
+func (vidx *VoteIndexer) increaseErrorsMetric(phase string) {
+	vidx.CounterVecMap[common.ErrorsTotalMetricName].With(prometheus.Labels{common.PhaseLabel: phase}).Inc()
+}
+
+// increase rolled back insert transactions by the error class
+func (vidx *VoteIndexer) increaseTxRollbackMetric(err error) {
+	var rollbackErr *repository.TxRollbackError
+	if !errors.As(err, &rollbackErr) {
+		return
+	}
+	vidx.CounterVecMap[common.TxRollbackTotalMetricName].With(prometheus.Labels{common.ReasonLabel: rollbackErr.Reason}).Inc()
+}
+
+// increase inserted votes by status, an unexpected shift of the distribution reveals status classification bugs
+func (vidx *VoteIndexer) increaseInsertedMetric(vvList []model.ValidatorVote) {
+	statusCounts := make(map[model.VoteStatus]int)
+	for _, vv := range vvList {
+		statusCounts[vv.Status]++
+	}
+
+	for status, count := range statusCounts {
+		vidx.CounterVecMap[common.InsertedTotalMetricName].
+			With(prometheus.Labels{common.StatusLabel: status.String()}).
+			Add(float64(count))
+	}
 }
 
 func (vidx *VoteIndexer) updatePrometheusMetrics(indexPointer int64, indexPointerTimestamp time.Time) {
 	vidx.MetricsMap[common.IndexPointerBlockHeightMetricName].Set(float64(indexPointer))
 	vidx.MetricsMap[common.IndexPointerBlockTimestampMetricName].Set((float64(indexPointerTimestamp.Unix())))
+	vidx.MetricsMap[common.LastSuccessTimestampMetricName].SetToCurrentTime()
 	vidx.Debugf("update prometheus metrics %d height", indexPointer)
 }
@@ -0,0 +1,43 @@
+package indexer
+
+import (
+	"github.com/cosmostation/cvms/internal/common/types"
+	"github.com/cosmostation/cvms/internal/packages/consensus/voteindexer/model"
+)
+
+// cometbft block id flags in the last commit signatures
+const (
+	BlockIDFlagUnknown int64 = iota
+	BlockIDFlagAbsent
+	BlockIDFlagCommit
+	BlockIDFlagNil
+)
+
+// StatusMapping maps raw block id flags of the last commit signatures into normalized vote statuses,
+// so that the repository always stores the same status values across chain versions.
+// NOTE: the proposer's committed signature is stored as proposed regardless of the mapping,
+// and unmapped flags fall back into the signature existence like before.
+type StatusMapping map[int64]model.VoteStatus
+
+// DefaultStatusMapping keeps the previous classification by the signature existence.
+//   - BLOCK_ID_FLAG_ABSENT(1) -> missed
+//   - BLOCK_ID_FLAG_COMMIT(2) -> committed
+//   - BLOCK_ID_FLAG_NIL(3) -> committed, because nil votes also have signatures
+var DefaultStatusMapping = StatusMapping{
+	BlockIDFlagAbsent: model.VoteStatusMissed,
+	BlockIDFlagCommit: model.VoteStatusCommitted,
+	BlockIDFlagNil:    model.VoteStatusCommitted,
+}
+
+// Status returns the normalized vote status of the signature
+func (sm StatusMapping) Status(signature types.Signature) model.VoteStatus {
+	if status, exist := sm[signature.BlockIDFlag]; exist {
+		return status
+	}
+
+	// Note that it used to be block.Block.LastCommit.Precommits[i] == nil
+	if signature.Signature == nil {
+		return model.VoteStatusMissed
+	}
+	return model.VoteStatusCommitted
+}
@@ -0,0 +1,75 @@
+package indexer
+
+import (
+	"sort"
+
+	"github.com/cosmostation/cvms/internal/packages/consensus/voteindexer/model"
+)
+
+// StatusTransitionFunc is called when a validator crosses between signed(committed or proposed) and missed
+type StatusTransitionFunc func(chainID string, validatorHexAddressID int64, from, to model.VoteStatus, height int64)
+
+type statusTransition struct {
+	validatorHexAddressID int64
+	from                  model.VoteStatus
+	to                    model.VoteStatus
+	height                int64
+}
+
+// SetOnStatusTransition registers a best-effort callback for status transitions versus the previous height.
+// NOTE: the callback is invoked in a separate goroutine after a successful insert, so a slow notifier doesn't block indexing.
+// the first indexed height after start doesn't emit transitions, because previous statuses are kept in memory only.
+func (vidx *VoteIndexer) SetOnStatusTransition(fn StatusTransitionFunc) *VoteIndexer {
+	vidx.onStatusTransition = fn
+	return vidx
+}
+
+// detectStatusTransitions compares the inserted votes with the last statuses by height order and updates the last statuses
+func (vidx *VoteIndexer) detectStatusTransitions(vvList []model.ValidatorVote) []statusTransition {
+	sorted := make([]model.ValidatorVote, len(vvList))
+	copy(sorted, vvList)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Height < sorted[j].Height })
+
+	transitions := make([]statusTransition, 0)
+	for _, vv := range sorted {
+		// unknown statuses are neither signed nor missed
+		if vv.Status == model.VoteStatusUnknown {
+			continue
+		}
+		prev, exist := vidx.lsm[vv.ValidatorHexAddressID]
+		vidx.lsm[vv.ValidatorHexAddressID] = vv.Status
+		if !exist {
+			continue
+		}
+		// NOTE: proposed and committed are same for alerting, otherwise the proposer rotation emits transitions every block
+		if (prev == model.VoteStatusMissed) == (vv.Status == model.VoteStatusMissed) {
+			continue
+		}
+		transitions = append(transitions, statusTransition{vv.ValidatorHexAddressID, prev, vv.Status, vv.Height})
+	}
+
+	return transitions
+}
+
+func (vidx *VoteIndexer) notifyStatusTransitions(vvList []model.ValidatorVote) {
+	if vidx.onStatusTransition == nil {
+		return
+	}
+
+	transitions := vidx.detectStatusTransitions(vvList)
+	if len(transitions) == 0 {
+		return
+	}
+
+	fn, chainID := vidx.onStatusTransition, vidx.ChainID
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				vidx.Errorf("recovered from panic in status transition callback: %v", r)
+			}
+		}()
+		for _, t := range transitions {
+			fn(chainID, t.validatorHexAddressID, t.from, t.to, t.height)
+		}
+	}()
+}
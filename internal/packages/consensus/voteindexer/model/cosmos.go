@@ -2,12 +2,14 @@ package model
 
 import (
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/uptrace/bun"
 )
 
-// status := 0 is NaN(jailed or inactive) 1 is missed, 2 is voted, 3 is proposed
+// status := 0 is unknown(partial block data) or NaN(jailed or inactive) 1 is missed, 2 is voted, 3 is proposed
+// timestamp is the block header time of the height, not the insertion time
 type ValidatorVote struct {
 	bun.BaseModel         `bun:"table:voteindexer"`
 	ID                    int64      `bun:"id,pk,autoincrement"`
@@ -16,6 +18,12 @@ type ValidatorVote struct {
 	ValidatorHexAddressID int64      `bun:"validator_hex_address_id,notnull"`
 	Status                VoteStatus `bun:"status,notnull"`
 	Timestamp             time.Time  `bun:"timestamp,notnull"`
+	// voting power snapshot at the height, nil falls back into the current voting power in validator_info
+	ValidatorVotePower *int64 `bun:"validator_vote_power"`
+	// optional raw block id flag of the signature for auditing the status mapping, nil when it isn't stored
+	RawFlag *int64 `bun:"raw_flag"`
+	// optional block id hash of the height for detecting reorgs, nil when it isn't stored
+	BlockHash *string `bun:"block_hash"`
 }
 
 func (vm ValidatorVote) String() string {
@@ -29,20 +37,192 @@ func (vm ValidatorVote) String() string {
 	)
 }
 
-// Methods
+// VoteStatus is stored as a smallint in the voteindexer table
 type VoteStatus int
 
 const (
-	Missed VoteStatus = iota + 1
-	Voted
-	Proposed
+	// the status couldn't be determined from partial block data, it's counted separately from the others
+	VoteStatusUnknown VoteStatus = iota
+	VoteStatusMissed
+	VoteStatusCommitted
+	VoteStatusProposed
 )
 
+func (vs VoteStatus) String() string {
+	switch vs {
+	case VoteStatusMissed:
+		return "missed"
+	case VoteStatusCommitted:
+		return "committed"
+	case VoteStatusProposed:
+		return "proposed"
+	default:
+		return "unknown"
+	}
+}
+
+// missed power is the sum of voting power snapshots at missed heights,
+// the current voting power is used for heights without snapshots
 type RecentValidatorVote struct {
-	Moniker       string `bun:"moniker"`
-	MaxHeight     int64  `bun:"max_height"`
-	MinHeight     int64  `bun:"min_height"`
-	ProposedCount int64  `bun:"proposed"`
-	CommitedCount int64  `bun:"commited"`
-	MissedCount   int64  `bun:"missed"`
+	ValidatorHexAddressID int64   `bun:"validator_hex_address_id" json:"validator_hex_address_id"`
+	Moniker               string  `bun:"moniker" json:"moniker"`
+	HexAddress            string  `bun:"hex_address" json:"hex_address,omitempty"`
+	ConsensusAddress      string  `bun:"-" json:"consensus_address,omitempty"`
+	VotingPower           int64   `bun:"voting_power" json:"voting_power"`
+	MaxHeight             int64   `bun:"max_height" json:"max_height"`
+	MinHeight             int64   `bun:"min_height" json:"min_height"`
+	ProposedCount         int64   `bun:"proposed" json:"proposed"`
+	CommitedCount         int64   `bun:"commited" json:"committed"`
+	MissedCount           int64   `bun:"missed" json:"missed"`
+	UnknownCount          int64   `bun:"unknown" json:"unknown"`
+	MissedPower           int64   `bun:"missed_power" json:"missed_power"`
+	MissRate              float64 `bun:"-" json:"miss_rate"`
+}
+
+// SetMissRate calculates missed votes ratio over all votes in the window, unknown votes are excluded
+func (rvv *RecentValidatorVote) SetMissRate() {
+	total := rvv.ProposedCount + rvv.CommitedCount + rvv.MissedCount
+	if total == 0 {
+		rvv.MissRate = 0
+		return
+	}
+	rvv.MissRate = float64(rvv.MissedCount) / float64(total)
+}
+
+// ChainLivenessSummary is a top-line aggregate over all validators in the recent window
+type ChainLivenessSummary struct {
+	TotalBlocks        int64   `bun:"total_blocks" json:"total_blocks"`
+	ValidatorsWithMiss int64   `bun:"validators_with_miss" json:"validators_with_miss"`
+	WorstMissRate      float64 `bun:"worst_miss_rate" json:"worst_miss_rate"`
+}
+
+// CrossChainMiss is an operator's miss summary on a chain over the recent window
+type CrossChainMiss struct {
+	ChainID               string  `bun:"-" json:"chain_id"`
+	ValidatorHexAddressID int64   `bun:"validator_hex_address_id" json:"validator_hex_address_id"`
+	Moniker               string  `bun:"moniker" json:"moniker"`
+	MissedCount           int64   `bun:"missed" json:"missed"`
+	TotalCount            int64   `bun:"total" json:"total"`
+	MissRate              float64 `bun:"-" json:"miss_rate"`
+}
+
+// ValidatorEvidence is a slashable evidence like double signing of a validator
+type ValidatorEvidence struct {
+	Height                int64  `bun:"height" json:"height"`
+	ValidatorHexAddressID int64  `bun:"validator_hex_address_id" json:"validator_hex_address_id"`
+	Moniker               string `bun:"moniker" json:"moniker"`
+	EvidenceType          string `bun:"evidence_type" json:"evidence_type"`
+}
+
+// HourlyVoteBucket is vote counts of a validator in an hour for sparkline charts, the hour is the bucket start in UTC
+type HourlyVoteBucket struct {
+	Hour           time.Time `bun:"hour" json:"hour"`
+	MissedCount    int64     `bun:"missed" json:"missed"`
+	CommittedCount int64     `bun:"committed" json:"committed"`
+	ProposedCount  int64     `bun:"proposed" json:"proposed"`
+}
+
+// DowntimeEvent is the longest consecutive missed streak of a validator in a time range.
+// the duration is between the block times of the first and the last missed heights
+type DowntimeEvent struct {
+	ValidatorHexAddressID int64         `bun:"validator_hex_address_id" json:"validator_hex_address_id"`
+	Moniker               string        `bun:"moniker" json:"moniker"`
+	StartHeight           int64         `bun:"start_height" json:"start_height"`
+	EndHeight             int64         `bun:"end_height" json:"end_height"`
+	StartTime             time.Time     `bun:"start_time" json:"start_time"`
+	EndTime               time.Time     `bun:"end_time" json:"end_time"`
+	MissedBlocks          int64         `bun:"missed_blocks" json:"missed_blocks"`
+	Duration              time.Duration `bun:"-" json:"duration"`
+}
+
+func (de *DowntimeEvent) SetDuration() {
+	de.Duration = de.EndTime.Sub(de.StartTime)
+}
+
+// HeightParticipation is signed validators over the active validators at a height for liveness charts.
+// the active validators are derived from distinct validators stored at the height
+type HeightParticipation struct {
+	Height      int64   `bun:"height" json:"height"`
+	SignedCount int64   `bun:"signed" json:"signed"`
+	TotalCount  int64   `bun:"total" json:"total"`
+	Rate        float64 `bun:"-" json:"rate"`
+}
+
+func (hp *HeightParticipation) SetRate() {
+	if hp.TotalCount == 0 {
+		hp.Rate = 0
+		return
+	}
+	hp.Rate = float64(hp.SignedCount) / float64(hp.TotalCount)
+}
+
+// StatusCounts is vote counts of a single validator over the recent window, total includes unknown rows
+type StatusCounts struct {
+	Missed    int64 `bun:"missed" json:"missed"`
+	Committed int64 `bun:"committed" json:"committed"`
+	Proposed  int64 `bun:"proposed" json:"proposed"`
+	Total     int64 `bun:"total" json:"total"`
+}
+
+// RawFlagCount is the row count of a raw block id flag and the status which it was mapped into.
+// the raw flag is nil for rows stored without it
+type RawFlagCount struct {
+	RawFlag *int64     `bun:"raw_flag" json:"raw_flag"`
+	Status  VoteStatus `bun:"status" json:"status"`
+	Count   int64      `bun:"count" json:"count"`
+}
+
+// ProposalCount is actual proposals of a validator and indexed blocks over the recent window,
+// for comparing with expected proposals by the voting power fraction
+type ProposalCount struct {
+	ValidatorHexAddressID int64  `bun:"validator_hex_address_id" json:"validator_hex_address_id"`
+	Moniker               string `bun:"moniker" json:"moniker"`
+	VotingPower           int64  `bun:"voting_power" json:"voting_power"`
+	Proposed              int64  `bun:"proposed" json:"proposed"`
+	Blocks                int64  `bun:"blocks" json:"blocks"`
+}
+
+// ProposerGap is the distance from a validator's last proposal to the latest indexed height.
+// the last proposed height is 0 when the validator never proposed in the indexed range
+type ProposerGap struct {
+	ValidatorHexAddressID   int64  `bun:"validator_hex_address_id" json:"validator_hex_address_id"`
+	Moniker                 string `bun:"moniker" json:"moniker"`
+	VotingPower             int64  `bun:"voting_power" json:"voting_power"`
+	LastProposedHeight      int64  `bun:"last_proposed_height" json:"last_proposed_height"`
+	BlocksSinceLastProposal int64  `bun:"blocks_since_last_proposal" json:"blocks_since_last_proposal"`
+}
+
+// ProposerFairness returns how evenly proposals are distributed relative to voting powers by validator hex address id.
+// it's 1 - total variation distance between proposal shares and voting power shares,
+// so 1 means proposals exactly follow stake and it goes to 0 as a few validators over-propose.
+// it returns 1 when there are no proposals or voting powers to compare.
+func ProposerFairness(proposalCounts map[int64]int64, votingPowers map[int64]int64) float64 {
+	var totalProposals, totalPower int64
+	for _, count := range proposalCounts {
+		totalProposals += count
+	}
+	for _, power := range votingPowers {
+		totalPower += power
+	}
+	if totalProposals == 0 || totalPower == 0 {
+		return 1
+	}
+
+	// proposers without any voting power are counted as fully unfair shares
+	ids := make(map[int64]bool, len(votingPowers))
+	for id := range votingPowers {
+		ids[id] = true
+	}
+	for id := range proposalCounts {
+		ids[id] = true
+	}
+
+	distance := 0.0
+	for id := range ids {
+		proposalShare := float64(proposalCounts[id]) / float64(totalProposals)
+		powerShare := float64(votingPowers[id]) / float64(totalPower)
+		distance += math.Abs(proposalShare - powerShare)
+	}
+
+	return 1 - distance/2
 }
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cosmostation/cvms/internal/packages/consensus/voteindexer/model"
+	"github.com/uptrace/bun"
+)
+
+// WithArchiveBeforeDelete returns a shallow copy of the repository which aggregates purged rows by the time retention
+// into the archive table as daily counts per validator before deleting them, for keeping long-term history.
+// the archive write and the delete share a transaction, so a purged row is never lost without being counted.
+// NOTE: it requires the archive table, see docker/postgres/schema/02-init-voteindexer.sql
+func (repo *VoteIndexerRepository) WithArchiveBeforeDelete(enabled bool) VoteIndexerRepository {
+	newRepo := *repo
+	newRepo.archiveBeforeDelete = enabled
+	return newRepo
+}
+
+// make archive table name like public.voteindexer__archive
+// NOTE: the double underscore keeps it out of the namespace of chain partition tables like public.voteindexer_<chain>
+func (repo *VoteIndexerRepository) archiveTableName() string {
+	return repo.parentTableName() + "__archive"
+}
+
+// archiveInsertQuery aggregates the rows into daily counts per validator and upserts them into the archive table
+func (repo *VoteIndexerRepository) archiveInsertQuery(source string) string {
+	return fmt.Sprintf(`
+	INSERT INTO %s AS archive (chain_info_id, validator_hex_address_id, day, total, missed)
+	SELECT 
+		chain_info_id,
+		validator_hex_address_id,
+		(timestamp AT TIME ZONE 'UTC')::date AS day,
+		COUNT(*) AS total,
+		COUNT(CASE WHEN status = %d THEN 1 END) AS missed
+	FROM %s
+	GROUP BY chain_info_id, validator_hex_address_id, day
+	ON CONFLICT (chain_info_id, validator_hex_address_id, day) DO UPDATE 
+	SET total = archive.total + EXCLUDED.total, missed = archive.missed + EXCLUDED.missed`,
+		repo.archiveTableName(), model.VoteStatusMissed, source)
+}
+
+// archiveAndDeleteOldRows deletes a chunk of old rows and archives them in a single statement,
+// data-modifying CTEs run in one snapshot so the archive and delete are atomic
+func (repo *VoteIndexerRepository) archiveAndDeleteOldRows(ctx context.Context, partitionTableName string, args ...interface{}) (int64, error) {
+	query := fmt.Sprintf(`
+	WITH deleted AS (
+		DELETE FROM %s WHERE (tableoid, ctid) IN (
+			SELECT tableoid, ctid FROM %s WHERE timestamp < ? LIMIT ?
+		)
+		RETURNING chain_info_id, validator_hex_address_id, status, timestamp
+	), archived AS (%s
+		RETURNING 1
+	)
+	SELECT COUNT(*) FROM deleted;
+	`, partitionTableName, partitionTableName, repo.archiveInsertQuery("deleted"))
+
+	var deletedRows int64
+	err := repo.NewRaw(query, args...).Scan(ctx, &deletedRows)
+	return deletedRows, err
+}
+
+// archiveAndDropTable archives the whole sub-partition and drops it in one transaction
+func (repo *VoteIndexerRepository) archiveAndDropTable(ctx context.Context, tableName string) error {
+	return repo.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewRaw(repo.archiveInsertQuery(tableName) + ";").Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.NewRaw(fmt.Sprintf(`DROP TABLE IF EXISTS %s;`, tableName)).Exec(ctx)
+		return err
+	})
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// EnsureBlockHashColumn adds the nullable block_hash column for block hashes of heights.
+// it's added on the parent table, so every partition has it. rows stored without block hashes keep null.
+func (repo *VoteIndexerRepository) EnsureBlockHashColumn(ctx context.Context) error {
+	return repo.ensureColumn(ctx, "block_hash", "TEXT")
+}
+
+// DetectReorg compares the stored block hash of the height with the expected block hash of the current chain.
+// true means the height was replaced by a reorg and the range from it should be reindexed.
+// NOTE: the hash should be hex encoded same as the /block rpc. it returns false when the height has no stored hash
+func (repo *VoteIndexerRepository) DetectReorg(ctx context.Context, chainID string, height int64, expectedBlockHash string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.timeoutFor(chainID))
+	defer cancel()
+
+	// Make partition table name
+	partitionTableName := repo.partitionTableName(chainID)
+
+	query := fmt.Sprintf(`
+	SELECT block_hash FROM %s
+	WHERE height = ? AND block_hash IS NOT NULL
+	LIMIT 1;
+	`, partitionTableName)
+
+	var storedBlockHash string
+	err := repo.reader().NewRaw(query, height).Scan(ctx, &storedBlockHash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to select block hash at %d height", height)
+	}
+
+	return storedBlockHash != expectedBlockHash, nil
+}
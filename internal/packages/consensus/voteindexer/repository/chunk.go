@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"reflect"
+
+	"github.com/cosmostation/cvms/internal/packages/consensus/voteindexer/model"
+	"github.com/uptrace/bun/dialect/pgdialect"
+)
+
+const (
+	// postgres limits bind parameters per statement
+	maxBindParameters = 65535
+
+	// default max rows per insert statement, it's far below the bind parameter ceiling
+	DefaultInsertChunkSize = 5000
+)
+
+// inserted columns of a validator vote except id, it's derived from the bun model so that new columns are counted
+var validatorVoteInsertColumns = len(pgdialect.New().Tables().Get(reflect.TypeOf(model.ValidatorVote{})).Fields) - 1
+
+// WithInsertChunkSize returns a shallow copy of the repository which splits inserts into statements of at most size rows.
+// the size is capped by the postgres bind parameter limit, and not positive size means the default.
+func (repo *VoteIndexerRepository) WithInsertChunkSize(size int) VoteIndexerRepository {
+	newRepo := *repo
+	newRepo.insertChunkSize = size
+	return newRepo
+}
+
+func (repo *VoteIndexerRepository) chunkSize() int {
+	size := repo.insertChunkSize
+	if size <= 0 {
+		size = DefaultInsertChunkSize
+	}
+	if maxRows := maxBindParameters / validatorVoteInsertColumns; size > maxRows {
+		size = maxRows
+	}
+	return size
+}
+
+// chunkValidatorVoteList splits the list into sub-slices of at most size rows without copying
+func chunkValidatorVoteList(ValidatorVoteList []model.ValidatorVote, size int) [][]model.ValidatorVote {
+	chunks := make([][]model.ValidatorVote, 0, (len(ValidatorVoteList)+size-1)/size)
+	for start := 0; start < len(ValidatorVoteList); start += size {
+		end := min(start+size, len(ValidatorVoteList))
+		chunks = append(chunks, ValidatorVoteList[start:end])
+	}
+	return chunks
+}
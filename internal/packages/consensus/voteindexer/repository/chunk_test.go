@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cosmostation/cvms/internal/packages/consensus/voteindexer/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
+	"github.com/uptrace/bun/driver/pgdriver"
+)
+
+// count bind parameters per row of the real insert query of the model
+func countInsertColumns(t *testing.T) int {
+	// NOTE: the db is only used for formatting the query, it never connects
+	db := bun.NewDB(sql.OpenDB(pgdriver.NewConnector()), pgdialect.New())
+	defer db.Close()
+
+	query := db.NewInsert().Model(&model.ValidatorVote{}).ExcludeColumn("id").String()
+	columns := query[strings.Index(query, "(")+1 : strings.Index(query, ")")]
+	return len(strings.Split(columns, ", "))
+}
+
+func TestValidatorVoteInsertColumns(t *testing.T) {
+	assert.Equal(t, countInsertColumns(t), validatorVoteInsertColumns)
+}
+
+func TestChunkValidatorVoteList(t *testing.T) {
+	// synthetic block of 20k validators
+	validatorCount := 20000
+	ValidatorVoteList := make([]model.ValidatorVote, validatorCount)
+	for idx := range ValidatorVoteList {
+		ValidatorVoteList[idx] = model.ValidatorVote{
+			ChainInfoID:           1,
+			Height:                100,
+			ValidatorHexAddressID: int64(idx + 1),
+			Status:                model.VoteStatusCommitted,
+			Timestamp:             time.Unix(1703140759, 0),
+		}
+	}
+
+	insertColumns := countInsertColumns(t)
+	testCases := []struct {
+		name           string
+		repo           VoteIndexerRepository
+		expectedChunks int
+	}{
+		{name: "default", repo: VoteIndexerRepository{}, expectedChunks: 4},
+		{name: "custom", repo: VoteIndexerRepository{insertChunkSize: 3000}, expectedChunks: 7},
+		{name: "capped", repo: VoteIndexerRepository{insertChunkSize: 100000}, expectedChunks: (validatorCount + maxBindParameters/insertColumns - 1) / (maxBindParameters / insertColumns)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			chunks := chunkValidatorVoteList(ValidatorVoteList, tc.repo.chunkSize())
+			assert.Len(t, chunks, tc.expectedChunks)
+
+			total := 0
+			for _, chunk := range chunks {
+				assert.LessOrEqual(t, len(chunk)*insertColumns, maxBindParameters)
+				total += len(chunk)
+			}
+			assert.Equal(t, validatorCount, total)
+			assert.Equal(t, int64(validatorCount), chunks[len(chunks)-1][len(chunks[len(chunks)-1])-1].ValidatorHexAddressID)
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// ensureColumn adds the nullable column on the parent table only when the catalog doesn't have it.
+// NOTE: ALTER TABLE takes an ACCESS EXCLUSIVE lock on the parent and every partition even when the column exists,
+// and it requires the table owner. so a deployment which already has the column never runs any DDL at start,
+// see docker/postgres/migrations/002-voteindexer-nullable-columns.sql for migrating existing deployments once.
+func (repo *VoteIndexerRepository) ensureColumn(ctx context.Context, column, dataType string) error {
+	ctx, cancel := context.WithTimeout(ctx, repo.sqlTimeout)
+	defer cancel()
+
+	var exists bool
+	err := repo.NewRaw(`
+	SELECT EXISTS (
+		SELECT 1
+		FROM information_schema.columns
+		WHERE table_schema = ? AND table_name = ? AND column_name = ?
+	);
+	`, repo.partitionSchema(), repo.indexName, column).Scan(ctx, &exists)
+	if err != nil {
+		return errors.Wrapf(err, "failed to check %s column of %s", column, repo.parentTableName())
+	}
+	if exists {
+		return nil
+	}
+
+	_, err = repo.NewRaw(fmt.Sprintf(
+		`ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s %s;`,
+		repo.parentTableName(), column, dataType,
+	)).Exec(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "failed to add %s column into %s", column, repo.parentTableName())
+	}
+
+	return nil
+}
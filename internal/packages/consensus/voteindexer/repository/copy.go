@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	idxmodel "github.com/cosmostation/cvms/internal/common/indexer/model"
+	"github.com/cosmostation/cvms/internal/packages/consensus/voteindexer/model"
+	"github.com/pkg/errors"
+	"github.com/uptrace/bun/driver/pgdriver"
+)
+
+// CopyValidatorVoteList bulk loads the list by postgres COPY FROM STDIN, it's much faster than multi-row inserts for backfills.
+// it falls back to the regular insert when the driver doesn't support COPY.
+// NOTE: COPY doesn't support any conflict clause, and it doesn't update the index pointer.
+// so, call UpdateIndexPointer in a separate transaction after the copy is completed.
+func (repo *VoteIndexerRepository) CopyValidatorVoteList(ctx context.Context, chainInfoID int64, ValidatorVoteList []model.ValidatorVote) error {
+	ctx, cancel := context.WithTimeout(ctx, repo.sqlTimeout)
+	defer cancel()
+
+	if len(ValidatorVoteList) == 0 {
+		return nil
+	}
+
+	// make sure height sub-partitions exist before copying
+	err := repo.ensureHeightPartitions(ctx, chainInfoID, ValidatorVoteList)
+	if err != nil {
+		return errors.Wrapf(err, "failed to ensure height partitions")
+	}
+
+	// fallback to the regular insert path
+	if _, ok := repo.DB.Driver().(pgdriver.Driver); !ok {
+		_, err := repo.NewInsert().
+			Model(&ValidatorVoteList).
+			ModelTableExpr(repo.parentTableName()).
+			ExcludeColumn("id").
+			On(onConflictDoNothing).
+			Exec(ctx)
+		if err != nil {
+			return errors.Wrapf(err, "failed to insert validator vote list")
+		}
+		return nil
+	}
+
+	// make copy data in text format
+	var buf bytes.Buffer
+	for _, vv := range ValidatorVoteList {
+		if vv.ChainInfoID != chainInfoID {
+			return errors.Errorf("unexpected chain_info_id %d in the list, expected %d", vv.ChainInfoID, chainInfoID)
+		}
+		// NOTE: \N is null in the text format
+		rawFlag := `\N`
+		if vv.RawFlag != nil {
+			rawFlag = strconv.FormatInt(*vv.RawFlag, 10)
+		}
+		votePower := `\N`
+		if vv.ValidatorVotePower != nil {
+			votePower = strconv.FormatInt(*vv.ValidatorVotePower, 10)
+		}
+		blockHash := `\N`
+		if vv.BlockHash != nil {
+			blockHash = *vv.BlockHash
+		}
+		fmt.Fprintf(&buf, "%d\t%d\t%d\t%d\t%s\t%s\t%s\t%s\n",
+			vv.ChainInfoID,
+			vv.Height,
+			vv.ValidatorHexAddressID,
+			vv.Status,
+			vv.Timestamp.UTC().Format(time.RFC3339Nano),
+			votePower,
+			rawFlag,
+			blockHash,
+		)
+	}
+
+	conn, err := repo.Conn(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get a db connection for copy")
+	}
+	defer conn.Close()
+
+	query := fmt.Sprintf(
+		`COPY %s (chain_info_id, height, validator_hex_address_id, status, timestamp, validator_vote_power, raw_flag, block_hash) FROM STDIN`,
+		repo.parentTableName(),
+	)
+	_, err = pgdriver.CopyFrom(ctx, conn, &buf, query)
+	if err != nil {
+		return errors.Wrapf(err, "failed to copy validator vote list")
+	}
+
+	return nil
+}
+
+func (repo *VoteIndexerRepository) UpdateIndexPointer(ctx context.Context, chainInfoID int64, indexPointerHeight int64) error {
+	ctx, cancel := context.WithTimeout(ctx, repo.sqlTimeout)
+	defer cancel()
+
+	_, err := repo.
+		NewUpdate().
+		Model(&idxmodel.IndexPointer{}).
+		ModelTableExpr(repo.metaTableExpr("index_pointer")).
+		Set("pointer = ?", indexPointerHeight).
+		Where("chain_info_id = ?", chainInfoID).
+		Where("index_name = ?", repo.indexName).
+		Exec(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "failed to update new index pointer")
+	}
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cosmostation/cvms/internal/packages/consensus/voteindexer/model"
+	"github.com/pkg/errors"
+)
+
+// make evidence table name like public.voteindexer__evidence
+// NOTE: the double underscore keeps it out of the namespace of chain partition tables like public.voteindexer_<chain>
+func (repo *VoteIndexerRepository) evidenceTableName() string {
+	return repo.parentTableName() + "__evidence"
+}
+
+// InsertEvidence records a slashable evidence like double signing of the validator at the height.
+// a duplicated evidence of the same type is ignored.
+// NOTE: it requires the evidence table, see docker/postgres/schema/02-init-voteindexer.sql
+func (repo *VoteIndexerRepository) InsertEvidence(ctx context.Context, chainID string, height int64, validatorHexAddressID int64, evidenceType string) error {
+	ctx, cancel := context.WithTimeout(ctx, repo.timeoutFor(chainID))
+	defer cancel()
+
+	query := fmt.Sprintf(`
+	INSERT INTO %s (chain_info_id, height, validator_hex_address_id, evidence_type)
+	SELECT ci.id, ?, ?, ? FROM %s ci WHERE ci.chain_id = ?
+	ON CONFLICT (chain_info_id, height, validator_hex_address_id, evidence_type) DO NOTHING;
+	`, repo.evidenceTableName(), repo.metaTableName("chain_info"))
+	_, err := repo.NewRaw(query, height, validatorHexAddressID, evidenceType, chainID).Exec(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "failed to insert %s evidence at %d height", evidenceType, height)
+	}
+
+	return nil
+}
+
+// SelectRecentEvidence returns evidences of the chain in the recent window from the latest indexed height
+func (repo *VoteIndexerRepository) SelectRecentEvidence(ctx context.Context, chainID string, window int64) ([]model.ValidatorEvidence, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.timeoutFor(chainID))
+	defer cancel()
+
+	window = repo.normalizeWindow(window)
+
+	// Make partition table name
+	partitionTableName := repo.partitionTableName(chainID)
+
+	// Make model
+	veList := make([]model.ValidatorEvidence, 0)
+	query := fmt.Sprintf(`
+	SELECT 
+		ev.height,
+		ev.validator_hex_address_id,
+		vi.moniker,
+		ev.evidence_type
+	FROM %s ev
+	JOIN %s ci ON ev.chain_info_id = ci.id
+	JOIN %s vi ON ev.validator_hex_address_id = vi.id
+	WHERE ci.chain_id = ? AND ev.height > (COALESCE((SELECT MAX(height) FROM %s), 0) - ?)
+	ORDER BY ev.height DESC;
+	`, repo.evidenceTableName(), repo.metaTableName("chain_info"), repo.metaTableName("validator_info"), partitionTableName)
+	err := repo.reader().NewRaw(query, chainID, window).Scan(ctx, &veList)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to select recent evidence")
+	}
+
+	return veList, nil
+}
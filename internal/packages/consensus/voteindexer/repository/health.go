@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	idxmodel "github.com/cosmostation/cvms/internal/common/indexer/model"
+	"github.com/pkg/errors"
+)
+
+// HealthCheck verifies preconditions of the vote indexer for the chain.
+// it checks the partition table exists and is writable, and validator_info and the index pointer row are present.
+func (repo *VoteIndexerRepository) HealthCheck(ctx context.Context, chainID string) error {
+	ctx, cancel := context.WithTimeout(ctx, repo.timeoutFor(chainID))
+	defer cancel()
+
+	// Make partition table name
+	partitionTableName := repo.partitionTableName(chainID)
+
+	// phase 1: check the partition table exists and is writable
+	var exists, writable bool
+	err := repo.NewRaw(`
+	SELECT 
+		to_regclass(?) IS NOT NULL AS exists,
+		COALESCE(has_table_privilege(to_regclass(?), 'INSERT'), false) AS writable;
+	`, partitionTableName, partitionTableName).Scan(ctx, &exists, &writable)
+	if err != nil {
+		return errors.Wrapf(err, "failed to check %s partition table", partitionTableName)
+	}
+	if !exists {
+		return errors.Errorf("health check failed: %s partition table doesn't exist", partitionTableName)
+	}
+	if !writable {
+		return errors.Errorf("health check failed: %s partition table isn't writable", partitionTableName)
+	}
+
+	// phase 2: check meta schema was migrated
+	validatorInfoTableName := repo.metaTableName("validator_info")
+	err = repo.NewRaw(`SELECT to_regclass(?) IS NOT NULL;`, validatorInfoTableName).Scan(ctx, &exists)
+	if err != nil {
+		return errors.Wrapf(err, "failed to check %s table", validatorInfoTableName)
+	}
+	if !exists {
+		return errors.Errorf("health check failed: %s table doesn't exist, the meta schema may not be migrated", validatorInfoTableName)
+	}
+
+	// phase 3: check the index pointer row for the chain
+	exists, err = repo.
+		NewSelect().
+		Model((*idxmodel.IndexPointer)(nil)).
+		ModelTableExpr(repo.metaTableExpr("index_pointer")).
+		Join(fmt.Sprintf("JOIN %s AS ci ON ci.id = index_pointer.chain_info_id", repo.metaTableName("chain_info"))).
+		Where("ci.chain_id = ?", chainID).
+		Where("index_pointer.index_name = ?", repo.indexName).
+		Exists(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to check index pointer")
+	}
+	if !exists {
+		return errors.Errorf("health check failed: index pointer for %s in %s chain doesn't exist", repo.indexName, chainID)
+	}
+
+	return nil
+}
+
+// Ping is a lightweight liveness check of the database connection for the health endpoint
+func (repo *VoteIndexerRepository) Ping(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, repo.sqlTimeout)
+	defer cancel()
+
+	var one int
+	err := repo.NewRaw(`SELECT 1;`).Scan(ctx, &one)
+	if err != nil {
+		return errors.Wrap(err, "failed to ping the database")
+	}
+
+	return nil
+}
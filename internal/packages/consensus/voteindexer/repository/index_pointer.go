@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+
+	idxmodel "github.com/cosmostation/cvms/internal/common/indexer/model"
+	"github.com/pkg/errors"
+	"github.com/uptrace/bun"
+)
+
+// EnsureIndexPointer inserts the index pointer row at the start height only when it's absent,
+// so that the pointer update in the insert path always finds a row for a fresh chain.
+// an existing pointer is never moved by this.
+func (repo *VoteIndexerRepository) EnsureIndexPointer(ctx context.Context, chainInfoID int64, startHeight int64) error {
+	ctx, cancel := context.WithTimeout(ctx, repo.sqlTimeout)
+	defer cancel()
+
+	_, err := repo.ensureIndexPointerQuery(chainInfoID, startHeight).Exec(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "failed to ensure index pointer for %d chain_info_id", chainInfoID)
+	}
+
+	return nil
+}
+
+// NOTE: it relies on the uniq_index_name_by_chain_info_id constraint on (chain_info_id, index_name)
+func (repo *VoteIndexerRepository) ensureIndexPointerQuery(chainInfoID int64, startHeight int64) *bun.InsertQuery {
+	return repo.
+		NewInsert().
+		Model(&idxmodel.IndexPointer{
+			ChainInfoID: chainInfoID,
+			IndexName:   repo.indexName,
+			Pointer:     startHeight,
+		}).
+		ModelTableExpr(repo.metaTableExpr("index_pointer")).
+		ExcludeColumn("id").
+		On("CONFLICT (chain_info_id, index_name) DO NOTHING")
+}
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/cosmostation/cvms/internal/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
+	"github.com/uptrace/bun/driver/pgdriver"
+)
+
+func TestEnsureIndexPointerQuery(t *testing.T) {
+	// NOTE: the db is only used for formatting the query, it never connects
+	db := bun.NewDB(sql.OpenDB(pgdriver.NewConnector()), pgdialect.New())
+	defer db.Close()
+
+	// fresh chain which has no index pointer row yet
+	repo := NewRepository(common.IndexerDB{DB: db}, 10*time.Second, nil)
+	query := repo.ensureIndexPointerQuery(1, 100).String()
+	assert.Equal(t,
+		`INSERT INTO "meta"."index_pointer" AS "index_pointer" ("chain_info_id", "index_name", "pointer") VALUES (1, 'voteindexer', 100) ON CONFLICT (chain_info_id, index_name) DO NOTHING`,
+		query,
+	)
+
+	// custom index name should be ensured as well
+	customRepo := repo.WithIndexName("voteindexer_v2")
+	assert.Contains(t, customRepo.ensureIndexPointerQuery(1, 100).String(), `'voteindexer_v2'`)
+}
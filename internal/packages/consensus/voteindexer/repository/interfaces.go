@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	indexerrepo "github.com/cosmostation/cvms/internal/common/indexer/repository"
+	"github.com/cosmostation/cvms/internal/packages/consensus/voteindexer/model"
+)
+
+// interface for the vote indexer repository, collectors depend on this instead of the concrete repository
+type IVoteIndexerRepository interface {
+	indexerrepo.IMetaRepository
+
+	IndexName() string
+	HeightPartitionInterval() int64
+	InvalidateValidatorInfoCache(chainID string)
+
+	// preconditions and locks
+	HealthCheck(ctx context.Context, chainID string) error
+	AcquireChainLock(ctx context.Context, chainInfoID int64) (release func(), err error)
+	EnsureIndexPointer(ctx context.Context, chainInfoID int64, startHeight int64) error
+	EnsureRetentionIndex(ctx context.Context, chainID string) error
+	EnsureVotePowerColumn(ctx context.Context) error
+	EnsureRawFlagColumn(ctx context.Context) error
+	EnsureBlockHashColumn(ctx context.Context) error
+	CreateHeightPartitionedTable(ctx context.Context, chainID string, chainInfoID int64) error
+
+	// inserts
+	InsertValidatorVoteList(ctx context.Context, chainInfoID int64, indexPointerHeight int64, ValidatorVoteList []model.ValidatorVote) (int64, error)
+	InsertEvidence(ctx context.Context, chainID string, height int64, validatorHexAddressID int64, evidenceType string) error
+
+	// selects
+	SelectIndexPointer(ctx context.Context, chainInfoID int64) (int64, error)
+	SelectIndexedHeightRange(ctx context.Context, chainID string) (int64, int64, error)
+	SelectLastIndexedTimestamp(ctx context.Context, chainID string) (time.Time, error)
+	SelectIndexCompleteness(ctx context.Context, chainID string, fromHeight, toHeight int64) (int64, int64, error)
+	SelectRecentMissValidatorVoteList(ctx context.Context, chainID string, window int64) ([]model.RecentValidatorVote, error)
+	SelectConsecutiveProposerGaps(ctx context.Context, chainID string, window int64) ([]model.ProposerGap, error)
+	SelectRecentProposalCounts(ctx context.Context, chainID string, window int64) ([]model.ProposalCount, error)
+	SelectPartitionRowCount(ctx context.Context, chainID string) (int64, error)
+
+	// time retention
+	DeleteOldValidatorVoteList(ctx context.Context, chainID, retentionPeriod string, batchSize int) (int64, error)
+	DropExpiredPartitions(ctx context.Context, chainID, retentionPeriod string) (droppedTables []string, deletedRows int64, err error)
+}
+
+var _ IVoteIndexerRepository = (*VoteIndexerRepository)(nil)
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"database/sql/driver"
+
+	"github.com/pkg/errors"
+)
+
+// ErrChainLockHeld is returned when another indexer process already holds the advisory lock of the chain
+var ErrChainLockHeld = errors.New("the chain is already being indexed by another process")
+
+// AcquireChainLock takes a session level advisory lock keyed on the index name and chain_info_id,
+// so that two indexer processes never write into the same partition.
+// NOTE: the lock is held by a dedicated connection until the release function is called.
+func (repo *VoteIndexerRepository) AcquireChainLock(ctx context.Context, chainInfoID int64) (release func(), err error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.sqlTimeout)
+	defer cancel()
+
+	conn, err := repo.Conn(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get a dedicated connection for the chain lock")
+	}
+
+	var acquired bool
+	err = conn.NewRaw(`SELECT pg_try_advisory_lock(hashtext(?), ?);`, repo.indexName, chainInfoID).Scan(ctx, &acquired)
+	if err != nil {
+		conn.Close()
+		return nil, errors.Wrapf(err, "failed to acquire the chain lock for chain_info_id %d", chainInfoID)
+	}
+	if !acquired {
+		conn.Close()
+		return nil, errors.Wrapf(ErrChainLockHeld, "%s for chain_info_id %d", repo.indexName, chainInfoID)
+	}
+
+	release = func() {
+		ctx, cancel := context.WithTimeout(context.Background(), repo.sqlTimeout)
+		defer cancel()
+
+		var unlocked bool
+		err := conn.NewRaw(`SELECT pg_advisory_unlock(hashtext(?), ?);`, repo.indexName, chainInfoID).Scan(ctx, &unlocked)
+		if err == nil && unlocked {
+			// returns the connection into the pool, the session lock was already released
+			conn.Close()
+			return
+		}
+
+		if err == nil {
+			err = errors.New("the lock wasn't held by the session")
+		}
+
+		// NOTE: closing the connection only returns it into the pool and the session keeps holding the lock,
+		// so the physical connection is discarded for postgres to release the lock when the session ends.
+		repo.logger.Warnf("failed to release the chain lock for chain_info_id %d, the connection will be discarded: %s", chainInfoID, err)
+		_ = conn.Raw(func(any) error { return driver.ErrBadConn })
+		conn.Close()
+	}
+
+	return release, nil
+}
@@ -0,0 +1,200 @@
+// Package mocks provides a hand-written mock of the vote indexer repository for collector tests without a database.
+package mocks
+
+import (
+	"context"
+	"time"
+
+	indexerrepo "github.com/cosmostation/cvms/internal/common/indexer/repository"
+	"github.com/cosmostation/cvms/internal/packages/consensus/voteindexer/model"
+	"github.com/cosmostation/cvms/internal/packages/consensus/voteindexer/repository"
+)
+
+// VoteIndexerRepository is a mock of repository.IVoteIndexerRepository.
+// each method calls the matching func field when it's set, otherwise it returns zero values.
+// meta repository methods are delegated to the embedded IMetaRepository, so set it when they are used.
+type VoteIndexerRepository struct {
+	indexerrepo.IMetaRepository
+
+	IndexNameValue               string
+	HeightPartitionIntervalValue int64
+
+	InvalidateValidatorInfoCacheFn      func(chainID string)
+	HealthCheckFn                       func(ctx context.Context, chainID string) error
+	AcquireChainLockFn                  func(ctx context.Context, chainInfoID int64) (func(), error)
+	EnsureIndexPointerFn                func(ctx context.Context, chainInfoID int64, startHeight int64) error
+	EnsureRetentionIndexFn              func(ctx context.Context, chainID string) error
+	EnsureVotePowerColumnFn             func(ctx context.Context) error
+	EnsureRawFlagColumnFn               func(ctx context.Context) error
+	EnsureBlockHashColumnFn             func(ctx context.Context) error
+	CreateHeightPartitionedTableFn      func(ctx context.Context, chainID string, chainInfoID int64) error
+	InsertValidatorVoteListFn           func(ctx context.Context, chainInfoID int64, indexPointerHeight int64, ValidatorVoteList []model.ValidatorVote) (int64, error)
+	InsertEvidenceFn                    func(ctx context.Context, chainID string, height int64, validatorHexAddressID int64, evidenceType string) error
+	SelectIndexPointerFn                func(ctx context.Context, chainInfoID int64) (int64, error)
+	SelectIndexedHeightRangeFn          func(ctx context.Context, chainID string) (int64, int64, error)
+	SelectLastIndexedTimestampFn        func(ctx context.Context, chainID string) (time.Time, error)
+	SelectIndexCompletenessFn           func(ctx context.Context, chainID string, fromHeight, toHeight int64) (int64, int64, error)
+	SelectRecentMissValidatorVoteListFn func(ctx context.Context, chainID string, window int64) ([]model.RecentValidatorVote, error)
+	SelectConsecutiveProposerGapsFn     func(ctx context.Context, chainID string, window int64) ([]model.ProposerGap, error)
+	SelectRecentProposalCountsFn        func(ctx context.Context, chainID string, window int64) ([]model.ProposalCount, error)
+	SelectPartitionRowCountFn           func(ctx context.Context, chainID string) (int64, error)
+	DeleteOldValidatorVoteListFn        func(ctx context.Context, chainID, retentionPeriod string, batchSize int) (int64, error)
+	DropExpiredPartitionsFn             func(ctx context.Context, chainID, retentionPeriod string) (droppedTables []string, deletedRows int64, err error)
+}
+
+// Compile-time Assertion
+var _ repository.IVoteIndexerRepository = (*VoteIndexerRepository)(nil)
+
+func (m *VoteIndexerRepository) IndexName() string {
+	return m.IndexNameValue
+}
+
+func (m *VoteIndexerRepository) HeightPartitionInterval() int64 {
+	return m.HeightPartitionIntervalValue
+}
+
+func (m *VoteIndexerRepository) InvalidateValidatorInfoCache(chainID string) {
+	if m.InvalidateValidatorInfoCacheFn != nil {
+		m.InvalidateValidatorInfoCacheFn(chainID)
+	}
+}
+
+func (m *VoteIndexerRepository) HealthCheck(ctx context.Context, chainID string) error {
+	if m.HealthCheckFn != nil {
+		return m.HealthCheckFn(ctx, chainID)
+	}
+	return nil
+}
+
+func (m *VoteIndexerRepository) AcquireChainLock(ctx context.Context, chainInfoID int64) (func(), error) {
+	if m.AcquireChainLockFn != nil {
+		return m.AcquireChainLockFn(ctx, chainInfoID)
+	}
+	return func() {}, nil
+}
+
+func (m *VoteIndexerRepository) EnsureIndexPointer(ctx context.Context, chainInfoID int64, startHeight int64) error {
+	if m.EnsureIndexPointerFn != nil {
+		return m.EnsureIndexPointerFn(ctx, chainInfoID, startHeight)
+	}
+	return nil
+}
+
+func (m *VoteIndexerRepository) EnsureRetentionIndex(ctx context.Context, chainID string) error {
+	if m.EnsureRetentionIndexFn != nil {
+		return m.EnsureRetentionIndexFn(ctx, chainID)
+	}
+	return nil
+}
+
+func (m *VoteIndexerRepository) EnsureVotePowerColumn(ctx context.Context) error {
+	if m.EnsureVotePowerColumnFn != nil {
+		return m.EnsureVotePowerColumnFn(ctx)
+	}
+	return nil
+}
+
+func (m *VoteIndexerRepository) EnsureRawFlagColumn(ctx context.Context) error {
+	if m.EnsureRawFlagColumnFn != nil {
+		return m.EnsureRawFlagColumnFn(ctx)
+	}
+	return nil
+}
+
+func (m *VoteIndexerRepository) EnsureBlockHashColumn(ctx context.Context) error {
+	if m.EnsureBlockHashColumnFn != nil {
+		return m.EnsureBlockHashColumnFn(ctx)
+	}
+	return nil
+}
+
+func (m *VoteIndexerRepository) CreateHeightPartitionedTable(ctx context.Context, chainID string, chainInfoID int64) error {
+	if m.CreateHeightPartitionedTableFn != nil {
+		return m.CreateHeightPartitionedTableFn(ctx, chainID, chainInfoID)
+	}
+	return nil
+}
+
+func (m *VoteIndexerRepository) InsertValidatorVoteList(ctx context.Context, chainInfoID int64, indexPointerHeight int64, ValidatorVoteList []model.ValidatorVote) (int64, error) {
+	if m.InsertValidatorVoteListFn != nil {
+		return m.InsertValidatorVoteListFn(ctx, chainInfoID, indexPointerHeight, ValidatorVoteList)
+	}
+	return int64(len(ValidatorVoteList)), nil
+}
+
+func (m *VoteIndexerRepository) InsertEvidence(ctx context.Context, chainID string, height int64, validatorHexAddressID int64, evidenceType string) error {
+	if m.InsertEvidenceFn != nil {
+		return m.InsertEvidenceFn(ctx, chainID, height, validatorHexAddressID, evidenceType)
+	}
+	return nil
+}
+
+func (m *VoteIndexerRepository) SelectIndexPointer(ctx context.Context, chainInfoID int64) (int64, error) {
+	if m.SelectIndexPointerFn != nil {
+		return m.SelectIndexPointerFn(ctx, chainInfoID)
+	}
+	return 0, nil
+}
+
+func (m *VoteIndexerRepository) SelectIndexedHeightRange(ctx context.Context, chainID string) (int64, int64, error) {
+	if m.SelectIndexedHeightRangeFn != nil {
+		return m.SelectIndexedHeightRangeFn(ctx, chainID)
+	}
+	return 0, 0, nil
+}
+
+func (m *VoteIndexerRepository) SelectLastIndexedTimestamp(ctx context.Context, chainID string) (time.Time, error) {
+	if m.SelectLastIndexedTimestampFn != nil {
+		return m.SelectLastIndexedTimestampFn(ctx, chainID)
+	}
+	return time.Time{}, nil
+}
+
+func (m *VoteIndexerRepository) SelectIndexCompleteness(ctx context.Context, chainID string, fromHeight, toHeight int64) (int64, int64, error) {
+	if m.SelectIndexCompletenessFn != nil {
+		return m.SelectIndexCompletenessFn(ctx, chainID, fromHeight, toHeight)
+	}
+	return 0, 0, nil
+}
+
+func (m *VoteIndexerRepository) SelectRecentMissValidatorVoteList(ctx context.Context, chainID string, window int64) ([]model.RecentValidatorVote, error) {
+	if m.SelectRecentMissValidatorVoteListFn != nil {
+		return m.SelectRecentMissValidatorVoteListFn(ctx, chainID, window)
+	}
+	return nil, nil
+}
+
+func (m *VoteIndexerRepository) SelectConsecutiveProposerGaps(ctx context.Context, chainID string, window int64) ([]model.ProposerGap, error) {
+	if m.SelectConsecutiveProposerGapsFn != nil {
+		return m.SelectConsecutiveProposerGapsFn(ctx, chainID, window)
+	}
+	return nil, nil
+}
+
+func (m *VoteIndexerRepository) SelectRecentProposalCounts(ctx context.Context, chainID string, window int64) ([]model.ProposalCount, error) {
+	if m.SelectRecentProposalCountsFn != nil {
+		return m.SelectRecentProposalCountsFn(ctx, chainID, window)
+	}
+	return nil, nil
+}
+
+func (m *VoteIndexerRepository) SelectPartitionRowCount(ctx context.Context, chainID string) (int64, error) {
+	if m.SelectPartitionRowCountFn != nil {
+		return m.SelectPartitionRowCountFn(ctx, chainID)
+	}
+	return 0, nil
+}
+
+func (m *VoteIndexerRepository) DeleteOldValidatorVoteList(ctx context.Context, chainID, retentionPeriod string, batchSize int) (int64, error) {
+	if m.DeleteOldValidatorVoteListFn != nil {
+		return m.DeleteOldValidatorVoteListFn(ctx, chainID, retentionPeriod, batchSize)
+	}
+	return 0, nil
+}
+
+func (m *VoteIndexerRepository) DropExpiredPartitions(ctx context.Context, chainID, retentionPeriod string) (droppedTables []string, deletedRows int64, err error) {
+	if m.DropExpiredPartitionsFn != nil {
+		return m.DropExpiredPartitionsFn(ctx, chainID, retentionPeriod)
+	}
+	return nil, 0, nil
+}
@@ -0,0 +1,328 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/cosmostation/cvms/internal/packages/consensus/voteindexer/model"
+	"github.com/pkg/errors"
+)
+
+// default heights per sub-partition when the height partitioning is enabled
+const DefaultHeightPartitionInterval int64 = 1_000_000
+
+// ensured sub-partitions cache, it's shared by shallow copies of the repository
+type heightPartitionCache struct {
+	sync.Mutex
+	// key is partition table name, false means the chain partition is a leaf table without sub-partitions
+	rangePartitioned map[string]bool
+	// key is sub-partition table name
+	ensured map[string]bool
+	// chain id by chain_info_id for inserts which only know chain_info_id
+	chainIDs map[int64]string
+}
+
+func newHeightPartitionCache() *heightPartitionCache {
+	return &heightPartitionCache{
+		rangePartitioned: make(map[string]bool),
+		ensured:          make(map[string]bool),
+		chainIDs:         make(map[int64]string),
+	}
+}
+
+// WithHeightPartitionInterval returns a shallow copy of the repository which keeps vote rows in height range
+// sub-partitions under each chain partition, 0 disables it.
+// NOTE: only chain partitions created by CreateHeightPartitionedTable have sub-partitions,
+// legacy chain partitions which are leaf tables are kept as they are.
+func (repo *VoteIndexerRepository) WithHeightPartitionInterval(interval int64) VoteIndexerRepository {
+	newRepo := *repo
+	newRepo.heightPartitionInterval = interval
+	return newRepo
+}
+
+func (repo *VoteIndexerRepository) HeightPartitionInterval() int64 {
+	return repo.heightPartitionInterval
+}
+
+// make sub-partition table name covering the height like public.voteindexer_cosmoshub_4_h1000000
+func (repo *VoteIndexerRepository) heightPartitionTableName(chainID string, height int64) (string, int64, int64) {
+	fromHeight := (height / repo.heightPartitionInterval) * repo.heightPartitionInterval
+	toHeight := fromHeight + repo.heightPartitionInterval
+	return fmt.Sprintf("%s_h%d", repo.partitionTableName(chainID), fromHeight), fromHeight, toHeight
+}
+
+// CreateHeightPartitionedTable creates the chain partition which is partitioned by height range again.
+// it should be called before the chain partition is created as a leaf table by the meta repository.
+func (repo *VoteIndexerRepository) CreateHeightPartitionedTable(ctx context.Context, chainID string, chainInfoID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, repo.timeoutFor(chainID))
+	defer cancel()
+
+	// Make partition table name
+	partitionTableName := repo.partitionTableName(chainID)
+
+	query := fmt.Sprintf(
+		`CREATE TABLE IF NOT EXISTS %s PARTITION OF %s FOR VALUES IN ('%d') PARTITION BY RANGE (height);`,
+		partitionTableName, repo.parentTableName(), chainInfoID,
+	)
+	_, err := repo.NewRaw(query).Exec(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create height partitioned table %s", partitionTableName)
+	}
+
+	return nil
+}
+
+// EnsureHeightPartition creates the sub-partition covering the height if missing.
+// it's a no-op when the height partitioning is disabled or the chain partition is a leaf table.
+func (repo *VoteIndexerRepository) EnsureHeightPartition(ctx context.Context, chainID string, height int64) error {
+	if repo.heightPartitionInterval <= 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, repo.timeoutFor(chainID))
+	defer cancel()
+
+	// Make partition table name
+	partitionTableName := repo.partitionTableName(chainID)
+	subPartitionTableName, fromHeight, toHeight := repo.heightPartitionTableName(chainID, height)
+
+	// NOTE: the shared lock is only held for the cache, so that chains don't wait for DDLs of each other.
+	// concurrent creations of the same sub-partition are safe by IF NOT EXISTS.
+	repo.hpc.Lock()
+	ensured := repo.hpc.ensured[subPartitionTableName]
+	rangePartitioned, exist := repo.hpc.rangePartitioned[partitionTableName]
+	repo.hpc.Unlock()
+
+	if ensured {
+		return nil
+	}
+
+	if !exist {
+		err := repo.NewRaw(
+			`SELECT EXISTS (SELECT 1 FROM pg_partitioned_table WHERE partrelid = to_regclass(?));`,
+			partitionTableName,
+		).Scan(ctx, &rangePartitioned)
+		if err != nil {
+			return errors.Wrapf(err, "failed to check %s is partitioned", partitionTableName)
+		}
+		repo.hpc.Lock()
+		repo.hpc.rangePartitioned[partitionTableName] = rangePartitioned
+		repo.hpc.Unlock()
+	}
+
+	if !rangePartitioned {
+		return nil
+	}
+
+	query := fmt.Sprintf(
+		`CREATE TABLE IF NOT EXISTS %s PARTITION OF %s FOR VALUES FROM (%d) TO (%d);`,
+		subPartitionTableName, partitionTableName, fromHeight, toHeight,
+	)
+	_, err := repo.NewRaw(query).Exec(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create height partition %s", subPartitionTableName)
+	}
+
+	repo.hpc.Lock()
+	repo.hpc.ensured[subPartitionTableName] = true
+	repo.hpc.Unlock()
+	return nil
+}
+
+// ensure every sub-partition covering heights in the list before inserting
+func (repo *VoteIndexerRepository) ensureHeightPartitions(ctx context.Context, chainInfoID int64, ValidatorVoteList []model.ValidatorVote) error {
+	if repo.heightPartitionInterval <= 0 || len(ValidatorVoteList) == 0 {
+		return nil
+	}
+
+	repo.hpc.Lock()
+	chainID, exist := repo.hpc.chainIDs[chainInfoID]
+	repo.hpc.Unlock()
+	if !exist {
+		var err error
+		chainID, err = repo.selectChainIDByChainInfoID(ctx, chainInfoID)
+		if err != nil {
+			return err
+		}
+		repo.hpc.Lock()
+		repo.hpc.chainIDs[chainInfoID] = chainID
+		repo.hpc.Unlock()
+	}
+
+	minHeight, maxHeight := ValidatorVoteList[0].Height, ValidatorVoteList[0].Height
+	for _, vv := range ValidatorVoteList {
+		minHeight = min(minHeight, vv.Height)
+		maxHeight = max(maxHeight, vv.Height)
+	}
+
+	for height := (minHeight / repo.heightPartitionInterval) * repo.heightPartitionInterval; height <= maxHeight; height += repo.heightPartitionInterval {
+		err := repo.EnsureHeightPartition(ctx, chainID, height)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DropExpiredPartitions drops height sub-partitions of the chain which are entirely older than the retention cutoff.
+// the remaining old rows in the boundary sub-partition or in a leaf chain partition are deleted by DeleteOldValidatorVoteList.
+// NOTE: DROP doesn't report the number of rows, so deleted rows counts rows of dropped sub-partitions by their estimates
+func (repo *VoteIndexerRepository) DropExpiredPartitions(ctx context.Context, chainID, retentionPeriod string) (
+	droppedTables []string,
+	deletedRows int64,
+	err error,
+) {
+	// Calculate cutoff time by retention period
+	cutoffTime, err := makeCutoffTime(retentionPeriod)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Make partition table name
+	partitionTableName := repo.partitionTableName(chainID)
+
+	subPartitionTableNames, err := func() ([]string, error) {
+		ctx, cancel := context.WithTimeout(ctx, repo.timeoutFor(chainID))
+		defer cancel()
+
+		tableNames := make([]string, 0)
+		err := repo.NewRaw(`
+		SELECT nmsp_child.nspname || '.' || child.relname
+		FROM pg_inherits
+		JOIN pg_class child ON pg_inherits.inhrelid = child.oid
+		JOIN pg_namespace nmsp_child ON nmsp_child.oid = child.relnamespace
+		WHERE pg_inherits.inhparent = to_regclass(?);
+		`, partitionTableName).Scan(ctx, &tableNames)
+		return tableNames, err
+	}()
+	if err != nil {
+		return nil, 0, errors.Wrapf(err, "failed to select sub-partitions of %s", partitionTableName)
+	}
+
+	droppedTables = make([]string, 0)
+	for _, subPartitionTableName := range subPartitionTableNames {
+		dropped, droppedRows, err := func() (bool, int64, error) {
+			ctx, cancel := context.WithTimeout(ctx, repo.timeoutFor(chainID))
+			defer cancel()
+
+			// NOTE: empty sub-partitions are for upcoming heights, so they're never dropped
+			var expired bool
+			err := repo.NewRaw(
+				fmt.Sprintf(`SELECT COALESCE(MAX(timestamp) < ?, false) FROM %s;`, subPartitionTableName),
+				cutoffTime,
+			).Scan(ctx, &expired)
+			if err != nil || !expired {
+				return false, 0, err
+			}
+
+			// count rows before the drop for the retention metrics
+			rows, err := repo.selectTableRowCount(ctx, subPartitionTableName)
+			if err != nil {
+				return false, 0, err
+			}
+
+			if repo.archiveBeforeDelete {
+				err = repo.archiveAndDropTable(ctx, subPartitionTableName)
+				return err == nil, rows, err
+			}
+
+			_, err = repo.NewRaw(fmt.Sprintf(`DROP TABLE IF EXISTS %s;`, subPartitionTableName)).Exec(ctx)
+			return err == nil, rows, err
+		}()
+		if err != nil {
+			return droppedTables, deletedRows, errors.Wrapf(err, "failed to drop expired partition %s", subPartitionTableName)
+		}
+
+		if dropped {
+			deletedRows += droppedRows
+			droppedTables = append(droppedTables, subPartitionTableName)
+			repo.hpc.Lock()
+			delete(repo.hpc.ensured, subPartitionTableName)
+			repo.hpc.Unlock()
+		}
+	}
+
+	// delete the remaining old rows which straddle the cutoff
+	rowsAffected, err := repo.DeleteOldValidatorVoteList(ctx, chainID, retentionPeriod, DefaultDeleteBatchSize)
+	deletedRows += rowsAffected
+	if err != nil {
+		return droppedTables, deletedRows, err
+	}
+
+	return droppedTables, deletedRows, nil
+}
+
+// select chain id of the chain_info_id for making the partition table name
+func (repo *VoteIndexerRepository) selectChainIDByChainInfoID(ctx context.Context, chainInfoID int64) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.sqlTimeout)
+	defer cancel()
+
+	var chainID string
+	err := repo.NewRaw(
+		fmt.Sprintf(`SELECT chain_id FROM %s WHERE id = ?;`, repo.metaTableName("chain_info")),
+		chainInfoID,
+	).Scan(ctx, &chainID)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to select chain_id by %d chain_info_id", chainInfoID)
+	}
+
+	return chainID, nil
+}
+
+// SelectPartitionRowCount returns the row count of the chain partition including its height sub-partitions.
+// it's a cheap estimate from pg_class.reltuples, and it falls back to an exact count when any leaf table was never analyzed.
+func (repo *VoteIndexerRepository) SelectPartitionRowCount(ctx context.Context, chainID string) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.timeoutFor(chainID))
+	defer cancel()
+
+	// Make partition table name
+	partitionTableName := repo.partitionTableName(chainID)
+
+	// NOTE: reltuples is -1 for tables which were never vacuumed or analyzed
+	var estimatedRows int64
+	var unanalyzed bool
+	err := repo.reader().NewRaw(`
+	SELECT 
+		COALESCE(SUM(GREATEST(c.reltuples, 0)), 0)::BIGINT AS estimated_rows,
+		COALESCE(BOOL_OR(c.reltuples < 0), false) AS unanalyzed
+	FROM pg_partition_tree(to_regclass(?)) pt
+	JOIN pg_class c ON c.oid = pt.relid
+	WHERE pt.isleaf;
+	`, partitionTableName).Scan(ctx, &estimatedRows, &unanalyzed)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to estimate row count of %s", partitionTableName)
+	}
+	if !unanalyzed {
+		return estimatedRows, nil
+	}
+
+	var exactRows int64
+	err = repo.reader().NewRaw(fmt.Sprintf(`SELECT COUNT(*) FROM %s;`, partitionTableName)).Scan(ctx, &exactRows)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to count rows of %s", partitionTableName)
+	}
+
+	return exactRows, nil
+}
+
+// select the estimated row count of the table, it's counted exactly when the table was never vacuumed or analyzed
+func (repo *VoteIndexerRepository) selectTableRowCount(ctx context.Context, tableName string) (int64, error) {
+	var estimatedRows int64
+	err := repo.NewRaw(`SELECT reltuples::BIGINT FROM pg_class WHERE oid = to_regclass(?);`, tableName).Scan(ctx, &estimatedRows)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to estimate row count of %s", tableName)
+	}
+	if estimatedRows >= 0 {
+		return estimatedRows, nil
+	}
+
+	var exactRows int64
+	err = repo.NewRaw(fmt.Sprintf(`SELECT COUNT(*) FROM %s;`, tableName)).Scan(ctx, &exactRows)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to count rows of %s", tableName)
+	}
+
+	return exactRows, nil
+}
@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// SetConnPoolLimits tunes the connection pool of the underlying sql.DB, and the replica's one when it's configured.
+// not positive values keep the database/sql defaults, which means unlimited open connections.
+// NOTE: the pool is shared by every indexer which uses the same IndexerDB, so it's enough to call it once per process.
+// each chain needs about 3 connections at peak (the indexing transaction, the metrics loop and the time retention),
+// so maxOpen = 3 * N chains and maxIdle = N chains with a 30m max lifetime are sensible defaults,
+// as long as maxOpen stays below the postgres max_connections (100 by default) minus other clients.
+func (repo *VoteIndexerRepository) SetConnPoolLimits(maxOpen, maxIdle int, maxLifetime time.Duration) {
+	for _, db := range []*bun.DB{repo.DB, repo.replicaDB} {
+		if db == nil {
+			continue
+		}
+		if maxOpen > 0 {
+			db.SetMaxOpenConns(maxOpen)
+		}
+		if maxIdle > 0 {
+			db.SetMaxIdleConns(maxIdle)
+		}
+		if maxLifetime > 0 {
+			db.SetConnMaxLifetime(maxLifetime)
+		}
+	}
+}
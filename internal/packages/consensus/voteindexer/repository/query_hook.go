@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/uptrace/bun"
+)
+
+var (
+	// string literals with escaped quotes like 'it''s' and E'\\x00'
+	stringLiteralRegexp = regexp.MustCompile(`(?:\b[eE])?'(?:[^']|'')*'`)
+	// number literals, digits in identifiers like voteindexer_cosmoshub_4 aren't on a word boundary
+	numberLiteralRegexp = regexp.MustCompile(`\b\d+(?:\.\d+)?(?:[eE][+-]?\d+)?\b`)
+)
+
+// redactQuery replaces string and number literals of the formatted query with placeholders
+func redactQuery(query string) string {
+	query = stringLiteralRegexp.ReplaceAllString(query, "?")
+	return numberLiteralRegexp.ReplaceAllString(query, "?")
+}
+
+// slowQueryHook logs queries slower than the threshold.
+// NOTE: the query template of bun is already formatted with bound values, so literals are redacted before logging
+type slowQueryHook struct {
+	threshold time.Duration
+	logger    *logrus.Entry
+}
+
+// Compile-time Assertion
+var _ bun.QueryHook = (*slowQueryHook)(nil)
+
+func (h *slowQueryHook) BeforeQuery(ctx context.Context, _ *bun.QueryEvent) context.Context {
+	return ctx
+}
+
+func (h *slowQueryHook) AfterQuery(_ context.Context, event *bun.QueryEvent) {
+	duration := time.Since(event.StartTime)
+	if duration < h.threshold {
+		return
+	}
+
+	h.logger.
+		WithField("operation", event.Operation()).
+		WithField("duration", duration.String()).
+		Warnf("slow query over %s: %s", h.threshold, redactQuery(event.QueryTemplate))
+}
+
+// WithSlowQueryThreshold returns a shallow copy of the repository which logs queries slower than the threshold.
+// 0 means disabled.
+func (repo *VoteIndexerRepository) WithSlowQueryThreshold(threshold time.Duration) VoteIndexerRepository {
+	newRepo := *repo
+	if threshold <= 0 {
+		return newRepo
+	}
+
+	// NOTE: WithNamedArg returns a clone of the db, so that the hook isn't registered into the db shared by other indexers
+	hook := &slowQueryHook{threshold: threshold, logger: repo.logger}
+	newRepo.DB = repo.DB.WithNamedArg("slow_query_threshold", threshold.String())
+	newRepo.DB.AddQueryHook(hook)
+	if repo.replicaDB != nil {
+		newRepo.replicaDB = repo.replicaDB.WithNamedArg("slow_query_threshold", threshold.String())
+		newRepo.replicaDB.AddQueryHook(hook)
+	}
+	return newRepo
+}
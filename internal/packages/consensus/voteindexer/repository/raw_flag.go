@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cosmostation/cvms/internal/packages/consensus/voteindexer/model"
+	"github.com/pkg/errors"
+)
+
+// EnsureRawFlagColumn adds the nullable raw_flag column for raw block id flags of signatures.
+// it's added on the parent table, so every partition has it. rows stored without raw flags keep null.
+func (repo *VoteIndexerRepository) EnsureRawFlagColumn(ctx context.Context) error {
+	return repo.ensureColumn(ctx, "raw_flag", "SMALLINT")
+}
+
+// SelectRawFlagDistribution returns row counts by the raw block id flag and the mapped status over the recent window,
+// so that a flag mapped into an unexpected status reveals a classification regression of the status mapping.
+func (repo *VoteIndexerRepository) SelectRawFlagDistribution(ctx context.Context, chainID string, window int64) ([]model.RawFlagCount, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.timeoutFor(chainID))
+	defer cancel()
+
+	window = repo.normalizeWindow(window)
+
+	// Make partition table name
+	partitionTableName := repo.partitionTableName(chainID)
+
+	// Make model
+	rfcList := make([]model.RawFlagCount, 0)
+	query := fmt.Sprintf(`
+	SELECT 
+		raw_flag,
+		status,
+		COUNT(*) AS count
+	FROM %s
+	WHERE height > ((SELECT MAX(height) FROM %s) - ?)
+	GROUP BY raw_flag, status
+	ORDER BY raw_flag NULLS FIRST, status;
+	`, partitionTableName, partitionTableName)
+	err := repo.reader().NewRaw(query, window).Scan(ctx, &rfcList)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to select raw flag distribution")
+	}
+
+	return rfcList, nil
+}
@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	idxmodel "github.com/cosmostation/cvms/internal/common/indexer/model"
+	"github.com/cosmostation/cvms/internal/packages/consensus/voteindexer/model"
+	"github.com/pkg/errors"
+	"github.com/uptrace/bun"
+)
+
+// ResetIndexPointer moves the index pointer back to the given height for reindexing.
+// the old and new pointers are recorded into meta.index_pointer_history with the optional reason.
+// moving the pointer forward skips blocks, so it's rejected unless force is true.
+func (repo *VoteIndexerRepository) ResetIndexPointer(ctx context.Context, chainInfoID int64, height int64, force bool, reason string) error {
+	ctx, cancel := context.WithTimeout(ctx, repo.sqlTimeout)
+	defer cancel()
+
+	if height < 0 {
+		return errors.Errorf("invalid index pointer height: %d", height)
+	}
+
+	err := repo.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		ip := &idxmodel.IndexPointer{}
+		err := tx.
+			NewSelect().
+			Model(ip).
+			ModelTableExpr(repo.metaTableExpr("index_pointer")).
+			Where("chain_info_id = ?", chainInfoID).
+			Where("index_name = ?", repo.indexName).
+			For("UPDATE").
+			Scan(ctx)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return errors.Errorf("not found index pointer for %s in %d chain_info_id", repo.indexName, chainInfoID)
+			}
+			return errors.Wrapf(err, "failed to select index pointer")
+		}
+
+		if height > ip.Pointer && !force {
+			return errors.Errorf("new index pointer %d is higher than current pointer %d, use force flag to skip blocks", height, ip.Pointer)
+		}
+
+		_, err = tx.
+			NewUpdate().
+			Model(&idxmodel.IndexPointer{}).
+			ModelTableExpr(repo.metaTableExpr("index_pointer")).
+			Set("pointer = ?", height).
+			Where("chain_info_id = ?", chainInfoID).
+			Where("index_name = ?", repo.indexName).
+			Exec(ctx)
+		if err != nil {
+			return errors.Wrapf(err, "failed to update index pointer")
+		}
+
+		// keep an audit trail in the same transaction
+		_, err = tx.
+			NewInsert().
+			Model(&idxmodel.IndexPointerHistory{
+				ChainInfoID: chainInfoID,
+				IndexName:   repo.indexName,
+				OldPointer:  ip.Pointer,
+				NewPointer:  height,
+				Reason:      reason,
+			}).
+			ModelTableExpr(repo.metaTableExpr("index_pointer_history")).
+			ExcludeColumn("id").
+			Exec(ctx)
+		if err != nil {
+			return errors.Wrapf(err, "failed to insert index pointer history")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to reset index pointer")
+	}
+
+	return nil
+}
+
+// DeleteValidatorVoteRange deletes vote rows in [fromHeight, toHeight] to clear a range before reindexing
+func (repo *VoteIndexerRepository) DeleteValidatorVoteRange(ctx context.Context, chainID string, fromHeight, toHeight int64) (
+	/* deleted rows */ int64,
+	/* unexpected error */ error,
+) {
+	ctx, cancel := context.WithTimeout(ctx, repo.timeoutFor(chainID))
+	defer cancel()
+
+	if fromHeight > toHeight {
+		return 0, errors.Errorf("invalid height range: from %d is higher than to %d", fromHeight, toHeight)
+	}
+
+	// Make partition table name
+	partitionTableName := repo.partitionTableName(chainID)
+
+	query := fmt.Sprintf(`DELETE FROM %s WHERE height BETWEEN ? AND ?`, partitionTableName)
+	res, err := repo.NewRaw(query, fromHeight, toHeight).Exec(ctx)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to delete validator vote range")
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to get deleted rows")
+	}
+
+	return rowsAffected, nil
+}
+
+// SelectVoteGapHeights returns missing [start, end] height ranges without any vote rows in [fromHeight, toHeight].
+// it drives a targeted reindex of only the gaps after crashes.
+func (repo *VoteIndexerRepository) SelectVoteGapHeights(ctx context.Context, chainID string, fromHeight, toHeight int64) ([][2]int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.timeoutFor(chainID))
+	defer cancel()
+
+	if fromHeight > toHeight {
+		return nil, errors.Errorf("invalid height range: from %d is higher than to %d", fromHeight, toHeight)
+	}
+
+	// Make partition table name
+	partitionTableName := repo.partitionTableName(chainID)
+
+	// NOTE: consecutive missing heights have the same (height - row_number), so they're grouped into one range
+	query := fmt.Sprintf(`
+	WITH missing AS (
+		SELECT h
+		FROM generate_series(?::bigint, ?::bigint) AS h
+		WHERE NOT EXISTS (SELECT 1 FROM %s vidx WHERE vidx.height = h)
+	)
+	SELECT 
+		MIN(h) AS start_height,
+		MAX(h) AS end_height
+	FROM (SELECT h, h - ROW_NUMBER() OVER (ORDER BY h) AS grp FROM missing) AS t
+	GROUP BY grp
+	ORDER BY start_height;
+	`, partitionTableName)
+
+	gaps := make([]struct {
+		StartHeight int64 `bun:"start_height"`
+		EndHeight   int64 `bun:"end_height"`
+	}, 0)
+	err := repo.reader().NewRaw(query, fromHeight, toHeight).Scan(ctx, &gaps)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to select vote gap heights")
+	}
+
+	ranges := make([][2]int64, 0, len(gaps))
+	for _, gap := range gaps {
+		ranges = append(ranges, [2]int64{gap.StartHeight, gap.EndHeight})
+	}
+
+	return ranges, nil
+}
+
+// UpdateValidatorVoteStatus corrects the status of a stored vote row in place without delete and reinsert
+func (repo *VoteIndexerRepository) UpdateValidatorVoteStatus(ctx context.Context, chainID string, height int64, validatorHexAddressID int64, newStatus model.VoteStatus) error {
+	return repo.UpdateValidatorVoteStatusList(ctx, chainID, height, map[int64]model.VoteStatus{validatorHexAddressID: newStatus})
+}
+
+// UpdateValidatorVoteStatusList corrects statuses of vote rows at the height in one transaction.
+// the key of statuses is validator_hex_address_id, it fails when any of rows doesn't exist.
+func (repo *VoteIndexerRepository) UpdateValidatorVoteStatusList(ctx context.Context, chainID string, height int64, statuses map[int64]model.VoteStatus) error {
+	ctx, cancel := context.WithTimeout(ctx, repo.timeoutFor(chainID))
+	defer cancel()
+
+	if len(statuses) == 0 {
+		return nil
+	}
+
+	// Make partition table name
+	partitionTableName := repo.partitionTableName(chainID)
+
+	query := fmt.Sprintf(`UPDATE %s SET status = ? WHERE height = ? AND validator_hex_address_id = ?`, partitionTableName)
+	err := repo.RunInTx(ctx, repo.txOptions, func(ctx context.Context, tx bun.Tx) error {
+		for validatorHexAddressID, newStatus := range statuses {
+			res, err := tx.NewRaw(query, newStatus, height, validatorHexAddressID).Exec(ctx)
+			if err != nil {
+				return errors.Wrapf(err, "failed to update vote status of %d validator", validatorHexAddressID)
+			}
+
+			rowsAffected, err := res.RowsAffected()
+			if err != nil {
+				return errors.Wrapf(err, "failed to get updated rows")
+			}
+			if rowsAffected == 0 {
+				return errors.Errorf("not found vote row of %d validator at %d height", validatorHexAddressID, height)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to update validator vote status list at %d height", height)
+	}
+
+	return nil
+}
+
+// SelectPointerHistory returns index pointer resets of the chain from the latest, limit is capped by MaxVoteHistoryLimit
+func (repo *VoteIndexerRepository) SelectPointerHistory(ctx context.Context, chainInfoID int64, limit int) ([]idxmodel.IndexPointerHistory, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.sqlTimeout)
+	defer cancel()
+
+	if limit <= 0 || limit > MaxVoteHistoryLimit {
+		limit = MaxVoteHistoryLimit
+	}
+
+	iphList := make([]idxmodel.IndexPointerHistory, 0)
+	err := repo.
+		NewSelect().
+		Model(&iphList).
+		ModelTableExpr(repo.metaTableExpr("index_pointer_history")).
+		Where("chain_info_id = ?", chainInfoID).
+		Where("index_name = ?", repo.indexName).
+		Order("created_at DESC", "id DESC").
+		Limit(limit).
+		Scan(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to select index pointer history")
+	}
+
+	return iphList, nil
+}
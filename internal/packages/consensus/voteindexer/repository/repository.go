@@ -2,7 +2,12 @@ package repository
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/cosmostation/cvms/internal/common"
@@ -11,142 +16,1844 @@ import (
 	dbhelper "github.com/cosmostation/cvms/internal/helper/db"
 	"github.com/cosmostation/cvms/internal/packages/consensus/voteindexer/model"
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
 )
 
-const IndexName = "voteindexer"
+const (
+	// default index name for the parent table and index pointer, it can be overridden by WithIndexName
+	DefaultIndexName = "voteindexer"
+
+	// default lookback blocks for recent vote queries
+	DefaultRecentWindow int64 = 100
+
+	// default max rows per delete statement for the time retention
+	DefaultDeleteBatchSize = 10000
+
+	// max rows per page for the vote history query
+	MaxVoteHistoryLimit = 1000
+
+	// default rows for the miss rate leaderboard query
+	DefaultLeaderboardLimit = 10
+
+	// default lookback blocks of the proposal deficit, it's longer than the recent window for small validators
+	DefaultProposalDeficitWindow int64 = 10_000
+
+	// default lookback blocks of the proposer gaps, longer gaps are reported as the window
+	DefaultProposerGapWindow int64 = 10_000
+
+	// max concurrent queries of the multi chain recent vote query
+	DefaultMultiChainWorkers = 8
+
+	// max heights of the participation query, which returns a row per height
+	MaxParticipationHeightRange int64 = 10_000
+
+	// max time range of the longest downtime query, which scans every row in the range
+	MaxDowntimeRange = 7 * 24 * time.Hour
+)
+
+// ErrUnexpectedEmptyVoteList is returned instead of advancing the index pointer when the empty vote list guard is enabled
+var ErrUnexpectedEmptyVoteList = errors.New("unexpected empty validator vote list for a chain which has validators")
+
+// scan model for counting queries grouped by validator
+type validatorCount struct {
+	ValidatorHexAddressID int64 `bun:"validator_hex_address_id"`
+	Count                 int64 `bun:"count"`
+}
 
 type VoteIndexerRepository struct {
 	sqlTimeout time.Duration
+	// optional sql timeout overrides by chain id, for chains which have huge partitions
+	chainTimeouts map[string]time.Duration
+	indexName     string
 	*bun.DB
 	indexerrepo.IMetaRepository
+	// optional read-only replica for heavy select queries
+	replicaDB *bun.DB
+	// count proposed votes as committed in the recent vote query, false by default for three-way split
+	collapseProposed bool
+	// tenant schema for partition tables and meta joins, empty means public and meta schemas
+	schema string
+	// optional bech32 valcons prefix to return consensus addresses in the recent vote query
+	valconsPrefix string
+	// max attempts for transient db errors in inserts
+	maxRetries int
+	logger     *logrus.Entry
+	// transaction options for the insert path, nil means the database default isolation
+	txOptions *sql.TxOptions
+	// heights per sub-partition under each chain partition, 0 means no sub-partitions
+	heightPartitionInterval int64
+	hpc                     *heightPartitionCache
+	// min deleted rows by the time retention to run ANALYZE on the partition, 0 means disabled
+	analyzeThreshold int64
+	// reject empty vote lists for chains which have validators, rather than advancing the pointer
+	emptyVoteListGuard bool
+	// validator ids by hex address, it's shared by shallow copies of the repository
+	vic *ValidatorInfoCache
+	// max rows per insert statement, 0 means DefaultInsertChunkSize
+	insertChunkSize int
+	// aggregate purged rows into the archive table before the time retention deletes them
+	archiveBeforeDelete bool
+	// commit each height of a batch insert separately for making progress on slow databases
+	partialBatchCommit bool
+	// blocks since the earliest indexed height of a new validator to be excluded from miss checks, 0 means disabled
+	gracePeriod int64
+	// optional partition table naming for existing deployments, nil means the default naming in the tenant schema
+	partitionNameFunc PartitionNameFunc
+	// max lookback blocks of windowed queries, 0 means DefaultMaxWindow
+	maxWindow int64
 }
 
-func NewRepository(indexerDB common.IndexerDB, sqlTimeout time.Duration) VoteIndexerRepository {
+// PartitionNameFunc makes the schema qualified partition table name of the chain
+type PartitionNameFunc func(indexName, chainID string) string
+
+// NOTE: every method derives its own timeout from the caller's context, sqlTimeout is applied on top of it as a cap
+// an optional read-only replica can be passed to isolate dashboard select queries from the indexing writes
+// nil logger means the standard logger
+func NewRepository(indexerDB common.IndexerDB, sqlTimeout time.Duration, logger *logrus.Entry, replicaDB ...common.IndexerDB) VoteIndexerRepository {
 	// Instantiate the meta repository
 	metarepo := indexerrepo.NewMetaRepository(indexerDB)
 
+	if logger == nil {
+		logger = logrus.NewEntry(logrus.StandardLogger())
+	}
+
+	var replica *bun.DB
+	if len(replicaDB) > 0 && replicaDB[0].DB != nil {
+		replica = replicaDB[0].DB
+	}
+
 	// Return a repository that implements both IMetaRepository and vote-specific logic
-	return VoteIndexerRepository{sqlTimeout, indexerDB.DB, metarepo}
+	return VoteIndexerRepository{
+		sqlTimeout:      sqlTimeout,
+		indexName:       DefaultIndexName,
+		DB:              indexerDB.DB,
+		IMetaRepository: metarepo,
+		replicaDB:       replica,
+		schema:          indexerDB.Schema,
+		maxRetries:      DefaultMaxRetries,
+		logger:          logger,
+		hpc:             newHeightPartitionCache(),
+		vic:             NewValidatorInfoCache(DefaultValidatorInfoCacheTTL),
+	}
+}
+
+// reader returns the replica DB for select queries, or the primary DB when no replica is configured.
+// NOTE: the index pointer is always read from the primary, because replica lag can make the indexer re-process blocks
+func (repo *VoteIndexerRepository) reader() *bun.DB {
+	if repo.replicaDB != nil {
+		return repo.replicaDB
+	}
+	return repo.DB
+}
+
+func (repo *VoteIndexerRepository) SQLTimeout() time.Duration {
+	return repo.sqlTimeout
+}
+
+// WithChainTimeouts returns a shallow copy of the repository with sql timeout overrides by chain id.
+// chains without any override use the default sql timeout.
+func (repo *VoteIndexerRepository) WithChainTimeouts(timeouts map[string]time.Duration) VoteIndexerRepository {
+	newRepo := *repo
+	newRepo.chainTimeouts = timeouts
+	return newRepo
+}
+
+// timeoutFor returns the sql timeout for the chain
+func (repo *VoteIndexerRepository) timeoutFor(chainID string) time.Duration {
+	if timeout, exist := repo.chainTimeouts[chainID]; exist && timeout > 0 {
+		return timeout
+	}
+	return repo.sqlTimeout
+}
+
+// WithTimeout returns a shallow copy of the repository with an overridden sql timeout.
+// it's useful for heavy historical queries like backfill selects without recreating the repository.
+func (repo *VoteIndexerRepository) WithTimeout(d time.Duration) VoteIndexerRepository {
+	newRepo := *repo
+	newRepo.sqlTimeout = d
+	return newRepo
+}
+
+// IndexName returns the parent table name which is also used as the index name of the index pointer
+func (repo *VoteIndexerRepository) IndexName() string {
+	return repo.indexName
+}
+
+// WithIndexName returns a shallow copy of the repository with another parent table and index pointer.
+// it allows side-by-side vote indexers in the same process without table collisions.
+func (repo *VoteIndexerRepository) WithIndexName(indexName string) VoteIndexerRepository {
+	newRepo := *repo
+	newRepo.indexName = indexName
+	return newRepo
+}
+
+// make partition table name in the tenant schema, or by the configured naming function
+func (repo *VoteIndexerRepository) partitionTableName(chainID string) string {
+	if repo.partitionNameFunc != nil {
+		return repo.partitionNameFunc(repo.indexName, chainID)
+	}
+	return dbhelper.MakePartitionTableNameWithSchema(repo.schema, repo.indexName, chainID)
+}
+
+// WithPartitionNameFunc returns a shallow copy of the repository which resolves partition tables by the naming function,
+// so that partitions of an older deployment are used without renaming them. nil restores the default naming.
+// NOTE: partitions of new chains are still created with the default naming by the meta repository
+func (repo *VoteIndexerRepository) WithPartitionNameFunc(fn PartitionNameFunc) VoteIndexerRepository {
+	newRepo := *repo
+	newRepo.partitionNameFunc = fn
+	return newRepo
+}
+
+// make the partitioned parent table name in the tenant schema
+func (repo *VoteIndexerRepository) parentTableName() string {
+	return fmt.Sprintf("%s.%s", repo.partitionSchema(), repo.indexName)
+}
+
+// make meta table name like validator_info in the tenant schema
+func (repo *VoteIndexerRepository) metaTableName(tableName string) string {
+	return dbhelper.MakeMetaTableName(repo.schema, tableName)
+}
+
+// make the model table expression of the meta table like index_pointer in the tenant schema
+func (repo *VoteIndexerRepository) metaTableExpr(tableName string) string {
+	return dbhelper.MakeMetaTableExpr(repo.schema, tableName)
+}
+
+// partitionSchema returns the schema of the parent and partition tables
+func (repo *VoteIndexerRepository) partitionSchema() string {
+	if repo.schema == "" {
+		return dbhelper.DefaultPartitionSchema
+	}
+	return repo.schema
+}
+
+// WithPartialBatchCommit returns a shallow copy of the repository which commits completed heights of a batch insert
+// before the sql timeout, so that a huge backfill batch makes forward progress instead of rolling back everything.
+func (repo *VoteIndexerRepository) WithPartialBatchCommit(enabled bool) VoteIndexerRepository {
+	newRepo := *repo
+	newRepo.partialBatchCommit = enabled
+	return newRepo
+}
+
+// WithGracePeriod returns a shallow copy of the repository which excludes validators from the miss rate leaderboard
+// and silent validator checks until the blocks passed since their earliest indexed height, for freshly-bonded validators.
+func (repo *VoteIndexerRepository) WithGracePeriod(blocks int64) VoteIndexerRepository {
+	newRepo := *repo
+	newRepo.gracePeriod = blocks
+	return newRepo
+}
+
+// graceFilter makes the condition excluding validators in the grace period, it's empty when the grace period is disabled
+func (repo *VoteIndexerRepository) graceFilter(partitionTableName string) (string, []interface{}) {
+	if repo.gracePeriod <= 0 {
+		return "", nil
+	}
+	return fmt.Sprintf(`
+	AND vi.id NOT IN (
+		SELECT validator_hex_address_id FROM %s 
+		GROUP BY validator_hex_address_id 
+		HAVING MIN(height) > ((SELECT MAX(height) FROM %s) - ?)
+	)`, partitionTableName, partitionTableName), []interface{}{repo.gracePeriod}
+}
+
+// WithEmptyVoteListGuard returns a shallow copy of the repository which treats an empty vote list as an error
+// when the chain has any validators in validator_info, instead of advancing the index pointer.
+// NOTE: keep it disabled for chains which legitimately have empty periods
+func (repo *VoteIndexerRepository) WithEmptyVoteListGuard(enabled bool) VoteIndexerRepository {
+	newRepo := *repo
+	newRepo.emptyVoteListGuard = enabled
+	return newRepo
+}
+
+// WithCollapsedProposed returns a shallow copy of the repository which counts proposed votes as committed.
+// it's for chains that only care about missed vs signed, the stored status is still granular.
+func (repo *VoteIndexerRepository) WithCollapsedProposed(collapse bool) VoteIndexerRepository {
+	newRepo := *repo
+	newRepo.collapseProposed = collapse
+	return newRepo
+}
+
+// WithValconsPrefix returns a shallow copy of the repository which fills bech32 consensus addresses
+// converted from validator hex addresses in the recent vote query, empty prefix disables it.
+func (repo *VoteIndexerRepository) WithValconsPrefix(prefix string) VoteIndexerRepository {
+	newRepo := *repo
+	newRepo.valconsPrefix = prefix
+	return newRepo
+}
+
+// WithTxOptions returns a shallow copy of the repository which runs the insert transactions with the options.
+// NOTE: ReadCommitted is the postgres default and the cheapest, but concurrent indexers can observe the pointer row
+// changed by others between statements. Serializable prevents that anomaly at the cost of serialization failures
+// under contention, which are retried as transient errors up to maxRetries.
+func (repo *VoteIndexerRepository) WithTxOptions(opts *sql.TxOptions) VoteIndexerRepository {
+	newRepo := *repo
+	newRepo.txOptions = opts
+	return newRepo
 }
 
 func (repo *VoteIndexerRepository) InsertValidatorVoteList(
+	ctx context.Context,
 	chainInfoID int64,
 	indexPointerHeight int64,
 	ValidatorVoteList []model.ValidatorVote,
-) error {
-	ctx, cancel := context.WithTimeout(context.Background(), repo.sqlTimeout)
-	defer cancel()
+) (
+	/* inserted rows */ int64,
+	/* unexpected error */ error,
+) {
+	logger := repo.logger.WithFields(logrus.Fields{"chain_info_id": chainInfoID, "height": indexPointerHeight})
 
 	// if there are not any miss validators in this block, just update index pointer
 	if len(ValidatorVoteList) == 0 {
+		ctx, cancel := context.WithTimeout(ctx, repo.sqlTimeout)
+		defer cancel()
+
+		// NOTE: an empty validator set from a buggy node would silently skip blocks
+		if repo.emptyVoteListGuard {
+			validatorCount, err := repo.
+				NewSelect().
+				TableExpr(repo.metaTableName("validator_info")).
+				Where("chain_info_id = ?", chainInfoID).
+				Count(ctx)
+			if err != nil {
+				return 0, errors.Wrapf(err, "failed to count validators for %d chain_info_id", chainInfoID)
+			}
+			if validatorCount > 0 {
+				logger.Errorf("%s, %d validators are in validator_info", ErrUnexpectedEmptyVoteList, validatorCount)
+				return 0, errors.Wrapf(ErrUnexpectedEmptyVoteList, "at %d height for %d chain_info_id", indexPointerHeight, chainInfoID)
+			}
+		}
+
 		_, err := repo.
 			NewUpdate().
 			Model(&idxmodel.IndexPointer{}).
+			ModelTableExpr(repo.metaTableExpr("index_pointer")).
 			Set("pointer = ?", indexPointerHeight).
 			Where("chain_info_id = ?", chainInfoID).
-			Where("index_name = ?", IndexName).
+			Where("index_name = ?", repo.indexName).
 			Exec(ctx)
 		if err != nil {
-			return errors.Wrapf(err, "failed to update new index pointer")
+			logger.Errorf("failed to update new index pointer: %s", err)
+			return 0, errors.Wrapf(err, "failed to update new index pointer to %d height for %d chain_info_id", indexPointerHeight, chainInfoID)
 		}
 
-		return nil
+		return 0, nil
 	}
 
-	// insert miss validators for this block and udpate index pointer in one transaction
-	err := repo.RunInTx(
-		ctx,
-		nil,
-		func(ctx context.Context, tx bun.Tx) error {
-			_, err := tx.NewInsert().
-				Model(&ValidatorVoteList).
-				ExcludeColumn("id").
-				Exec(ctx)
-			if err != nil {
-				return errors.Wrapf(err, "failed to insert validator_miss list")
-			}
+	// make sure height sub-partitions exist before inserting
+	err := repo.ensureHeightPartitions(ctx, chainInfoID, ValidatorVoteList)
+	if err != nil {
+		logger.Errorf("failed to ensure height partitions: %s", err)
+		return 0, errors.Wrapf(err, "failed to ensure height partitions at %d height for %d chain_info_id", indexPointerHeight, chainInfoID)
+	}
 
-			_, err = tx.
-				NewUpdate().
-				Model(&idxmodel.IndexPointer{}).
-				Set("pointer = ?", indexPointerHeight).
-				Where("chain_info_id = ?", chainInfoID).
-				Where("index_name = ?", IndexName).
-				Exec(ctx)
-			if err != nil {
-				return errors.Wrapf(err, "failed to update new index pointer")
-			}
+	// insert miss validators for this block and udpate index pointer in one transaction.
+	// NOTE: the transaction is retried on transient errors like failovers, the timeout is applied per attempt
+	var inserted int64
+	err = repo.retry(ctx, logger, "insert validator vote list", func(ctx context.Context) error {
+		return repo.RunInTx(
+			ctx,
+			repo.txOptions,
+			func(ctx context.Context, tx bun.Tx) error {
+				var err error
+				inserted, err = repo.insertValidatorVoteListTx(ctx, tx, chainInfoID, indexPointerHeight, ValidatorVoteList)
+				return err
+			})
+	})
+
+	if err != nil {
+		logger.Errorf("failed to exec validator miss in a transaction: %s", err)
+		err = newTxRollbackError(err)
+		return 0, errors.Wrapf(err, "failed to exec validator miss in a transaction at %d height for %d chain_info_id", indexPointerHeight, chainInfoID)
+	}
+
+	return inserted, nil
+}
+
+// InsertValidatorVoteListTx inserts the list and updates the index pointer on the given transaction without committing.
+// it makes the vote write composable with other writes in a larger atomic unit, the caller owns commit and rollback.
+func (repo *VoteIndexerRepository) InsertValidatorVoteListTx(
+	ctx context.Context,
+	tx bun.Tx,
+	chainInfoID int64,
+	indexPointerHeight int64,
+	ValidatorVoteList []model.ValidatorVote,
+) error {
+	// NOTE: sub-partitions are created out of the transaction, DDL on the partitioned table shouldn't be rolled back with votes
+	err := repo.ensureHeightPartitions(ctx, chainInfoID, ValidatorVoteList)
+	if err != nil {
+		return errors.Wrapf(err, "failed to ensure height partitions")
+	}
+
+	_, err = repo.insertValidatorVoteListTx(ctx, tx, chainInfoID, indexPointerHeight, ValidatorVoteList)
+	return err
+}
+
+func (repo *VoteIndexerRepository) insertValidatorVoteListTx(
+	ctx context.Context,
+	tx bun.Tx,
+	chainInfoID int64,
+	indexPointerHeight int64,
+	ValidatorVoteList []model.ValidatorVote,
+) (
+	/* inserted rows */ int64,
+	/* unexpected error */ error,
+) {
+	// NOTE: split the list for huge validator sets to stay below the bind parameter ceiling
+	var inserted int64
+	for _, chunk := range chunkValidatorVoteList(ValidatorVoteList, repo.chunkSize()) {
+		res, err := tx.NewInsert().
+			Model(&chunk).
+			ModelTableExpr(repo.parentTableName()).
+			ExcludeColumn("id").
+			On(onConflictDoNothing).
+			Exec(ctx)
+		if err != nil {
+			return 0, errors.Wrapf(err, "failed to insert validator_miss list")
+		}
+
+		rowsAffected, err := res.RowsAffected()
+		if err != nil {
+			return 0, errors.Wrapf(err, "failed to get inserted rows")
+		}
+		inserted += rowsAffected
+	}
+
+	_, err := tx.
+		NewUpdate().
+		Model(&idxmodel.IndexPointer{}).
+		ModelTableExpr(repo.metaTableExpr("index_pointer")).
+		Set("pointer = ?", indexPointerHeight).
+		Where("chain_info_id = ?", chainInfoID).
+		Where("index_name = ?", repo.indexName).
+		Exec(ctx)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to update new index pointer")
+	}
+
+	return inserted, nil
+}
+
+// InsertValidatorVoteListBatch inserts votes of many heights and updates the index pointer to the final pointer.
+// it returns the committed index pointer, which is the final pointer on success and 0 when nothing was committed.
+// NOTE: in the partial batch commit mode, each height is committed by its own transaction under the shared sql timeout.
+// when the timeout is reached, the committed heights are kept and the committed pointer is returned without any error,
+// so a pointer less than the final pointer means that the next pass should continue from there.
+func (repo *VoteIndexerRepository) InsertValidatorVoteListBatch(
+	ctx context.Context,
+	chainInfoID int64,
+	batches map[int64][]model.ValidatorVote,
+	finalPointer int64,
+) (
+	/* committed pointer */ int64,
+	/* unexpected error */ error,
+) {
+	logger := repo.logger.WithFields(logrus.Fields{"chain_info_id": chainInfoID, "height": finalPointer})
+
+	// sort heights to insert the batch in ascending order
+	heights := make([]int64, 0, len(batches))
+	for height := range batches {
+		heights = append(heights, height)
+	}
+	sort.Slice(heights, func(i, j int) bool { return heights[i] < heights[j] })
+
+	// flatten the batch, heights without any votes are just skipped
+	ValidatorVoteList := make([]model.ValidatorVote, 0)
+	for _, height := range heights {
+		ValidatorVoteList = append(ValidatorVoteList, batches[height]...)
+	}
+
+	// make sure height sub-partitions exist before inserting
+	err := repo.ensureHeightPartitions(ctx, chainInfoID, ValidatorVoteList)
+	if err != nil {
+		logger.Errorf("failed to ensure height partitions: %s", err)
+		return 0, errors.Wrapf(err, "failed to ensure height partitions at %d height for %d chain_info_id", finalPointer, chainInfoID)
+	}
+
+	if repo.partialBatchCommit {
+		ctx, cancel := context.WithTimeout(ctx, repo.sqlTimeout)
+		defer cancel()
+		return repo.insertValidatorVoteListBatchPartially(ctx, chainInfoID, heights, batches, finalPointer)
+	}
 
-			return nil
+	// insert all heights' votes and update index pointer only once in one transaction.
+	// NOTE: the transaction is retried on transient errors like failovers, the timeout is applied per attempt
+	err = repo.retry(ctx, logger, "insert validator vote list batch", func(ctx context.Context) error {
+		return repo.RunInTx(
+			ctx,
+			repo.txOptions,
+			func(ctx context.Context, tx bun.Tx) error {
+				_, err := repo.insertValidatorVoteListTx(ctx, tx, chainInfoID, finalPointer, ValidatorVoteList)
+				return err
+			})
+	})
+
+	if err != nil {
+		logger.Errorf("failed to exec validator vote batch in a transaction: %s", err)
+		err = newTxRollbackError(err)
+		return 0, errors.Wrapf(err, "failed to exec validator vote batch in a transaction at %d height for %d chain_info_id", finalPointer, chainInfoID)
+	}
+
+	return finalPointer, nil
+}
+
+// insertValidatorVoteListBatchPartially commits each height in ascending order with the pointer at the height,
+// the last height is committed with the final pointer. a replayed height by the next pass is skipped by the conflict clause.
+func (repo *VoteIndexerRepository) insertValidatorVoteListBatchPartially(
+	ctx context.Context,
+	chainInfoID int64,
+	heights []int64,
+	batches map[int64][]model.ValidatorVote,
+	finalPointer int64,
+) (int64, error) {
+	// NOTE: an empty batch only updates the pointer into the final pointer
+	if len(heights) == 0 {
+		heights = []int64{finalPointer}
+	}
+
+	var committedPointer int64
+	for idx, height := range heights {
+		pointer := height
+		if idx == len(heights)-1 {
+			pointer = finalPointer
+		}
+
+		err := repo.RunInTx(ctx, repo.txOptions, func(ctx context.Context, tx bun.Tx) error {
+			_, err := repo.insertValidatorVoteListTx(ctx, tx, chainInfoID, pointer, batches[height])
+			return err
 		})
+		if err != nil {
+			if committedPointer > 0 && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				repo.logger.Warnf("stopped the partial batch commit by the timeout at %d height, committed pointer is %d", height, committedPointer)
+				return committedPointer, nil
+			}
+			return committedPointer, errors.Wrapf(err, "failed to commit validator vote batch at %d height", height)
+		}
+		committedPointer = pointer
+	}
+
+	return committedPointer, nil
+}
+
+func (repo *VoteIndexerRepository) SelectIndexPointer(ctx context.Context, chainInfoID int64) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.sqlTimeout)
+	defer cancel()
 
+	ip := &idxmodel.IndexPointer{}
+	err := repo.
+		NewSelect().
+		Model(ip).
+		ModelTableExpr(repo.metaTableExpr("index_pointer")).
+		Where("chain_info_id = ?", chainInfoID).
+		Where("index_name = ?", repo.indexName).
+		Scan(ctx)
 	if err != nil {
-		return errors.Wrapf(err, "failed to exec validator miss in a transaction")
+		if err == sql.ErrNoRows {
+			return 0, errors.Errorf("not found index pointer for %s in %d chain_info_id, the package may not be initialized", repo.indexName, chainInfoID)
+		}
+		return 0, errors.Wrapf(err, "failed to select index pointer")
 	}
 
-	return nil
+	return ip.Pointer, nil
 }
 
-func (repo *VoteIndexerRepository) SelectRecentMissValidatorVoteList(chainID string) ([]model.RecentValidatorVote, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), repo.sqlTimeout)
+// SelectIndexedHeightRange returns the earliest and latest indexed heights in the partition, (0, 0) when it's empty
+func (repo *VoteIndexerRepository) SelectIndexedHeightRange(ctx context.Context, chainID string) (
+	/* min height */ int64,
+	/* max height */ int64,
+	/* unexpected error */ error,
+) {
+	ctx, cancel := context.WithTimeout(ctx, repo.timeoutFor(chainID))
 	defer cancel()
 
 	// Make partition table name
-	partitionTableName := dbhelper.MakePartitionTableName(IndexName, chainID)
+	partitionTableName := repo.partitionTableName(chainID)
+
+	query := fmt.Sprintf(`SELECT COALESCE(MIN(height), 0), COALESCE(MAX(height), 0) FROM %s;`, partitionTableName)
+
+	var minHeight, maxHeight int64
+	err := repo.reader().NewRaw(query).Scan(ctx, &minHeight, &maxHeight)
+	if err != nil {
+		return 0, 0, errors.Wrapf(err, "failed to select indexed height range")
+	}
+
+	return minHeight, maxHeight, nil
+}
+
+// SelectLastIndexedTimestamp returns the latest block time in the partition, zero time when it's empty
+func (repo *VoteIndexerRepository) SelectLastIndexedTimestamp(ctx context.Context, chainID string) (time.Time, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.timeoutFor(chainID))
+	defer cancel()
+
+	// Make partition table name
+	partitionTableName := repo.partitionTableName(chainID)
+
+	// NOTE: timestamp of the max height is read by the height index rather than scanning every timestamp
+	query := fmt.Sprintf(`SELECT MAX(timestamp) FROM %s WHERE height = (SELECT MAX(height) FROM %s);`, partitionTableName, partitionTableName)
+
+	var lastTimestamp sql.NullTime
+	err := repo.reader().NewRaw(query).Scan(ctx, &lastTimestamp)
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "failed to select last indexed timestamp")
+	}
+
+	return lastTimestamp.Time, nil
+}
+
+// SelectIndexCompleteness compares expected heights in the range with distinct heights actually stored for detecting silent data loss.
+// NOTE: in the validator mode, heights where the filtered validators weren't in the active set have no rows as well.
+func (repo *VoteIndexerRepository) SelectIndexCompleteness(ctx context.Context, chainID string, fromHeight, toHeight int64) (
+	/* expected heights */ int64,
+	/* actual heights */ int64,
+	/* unexpected error */ error,
+) {
+	ctx, cancel := context.WithTimeout(ctx, repo.timeoutFor(chainID))
+	defer cancel()
+
+	if toHeight < fromHeight {
+		return 0, 0, errors.Errorf("invalid height range: from %d is over to %d", fromHeight, toHeight)
+	}
+
+	// Make partition table name
+	partitionTableName := repo.partitionTableName(chainID)
+
+	var actual int64
+	err := repo.reader().NewRaw(
+		fmt.Sprintf(`SELECT COUNT(DISTINCT height) FROM %s WHERE height BETWEEN ? AND ?;`, partitionTableName),
+		fromHeight, toHeight,
+	).Scan(ctx, &actual)
+	if err != nil {
+		return 0, 0, errors.Wrapf(err, "failed to select index completeness from %d to %d height", fromHeight, toHeight)
+	}
+
+	return toHeight - fromHeight + 1, actual, nil
+}
+
+// recentMissQuery makes the query and arguments of SelectRecentMissValidatorVoteList, it's shared with ExplainRecentMiss
+func (repo *VoteIndexerRepository) recentMissQuery(chainID string, window int64) (string, []interface{}) {
+	window = repo.normalizeWindow(window)
+
+	// Make partition table name
+	partitionTableName := repo.partitionTableName(chainID)
 
-	// Make model
-	rvvList := make([]model.RecentValidatorVote, 0)
 	query := fmt.Sprintf(`
 	SELECT 
+		vi.id AS validator_hex_address_id,
 		vi.moniker, 
+		vi.hex_address,
+		vi.voting_power,
     	MAX(vidx.height) AS max_height,    
     	MIN(vidx.height) AS min_height,
-    	COUNT(CASE WHEN status = 1 THEN 1 END) AS missed,
-    	COUNT(CASE WHEN status = 2 THEN 1 END) AS commited,
-    	COUNT(CASE WHEN status = 3 THEN 1 END) AS proposed
+    	COUNT(CASE WHEN status = ? THEN 1 END) AS missed,
+    	COUNT(CASE WHEN status IN (?) THEN 1 END) AS commited,
+    	COUNT(CASE WHEN status = ? AND NOT ? THEN 1 END) AS proposed,
+    	COUNT(CASE WHEN status = ? THEN 1 END) AS unknown,
+    	COALESCE(SUM(CASE WHEN status = ? THEN COALESCE(vidx.validator_vote_power, vi.voting_power) END), 0) AS missed_power
 	FROM %s vidx
-	JOIN meta.validator_info vi ON vidx.validator_hex_address_id = vi.id
-	WHERE height > ((SELECT MAX(height) FROM %s) - 100)
-	GROUP BY vi.moniker;
-	`, partitionTableName, partitionTableName)
-	err := repo.NewRaw(query).Scan(ctx, &rvvList)
+	JOIN %s vi ON vidx.validator_hex_address_id = vi.id
+	WHERE height > ((SELECT MAX(height) FROM %s) - ?)
+	GROUP BY vi.id, vi.moniker, vi.hex_address, vi.voting_power;
+	`, partitionTableName, repo.metaTableName("validator_info"), partitionTableName)
+
+	// collapse proposed status into committed when the option is enabled
+	committedStatuses := []model.VoteStatus{model.VoteStatusCommitted}
+	if repo.collapseProposed {
+		committedStatuses = append(committedStatuses, model.VoteStatusProposed)
+	}
+
+	return query, []interface{}{
+		model.VoteStatusMissed, bun.In(committedStatuses), model.VoteStatusProposed, repo.collapseProposed,
+		model.VoteStatusUnknown,
+		model.VoteStatusMissed,
+		window,
+	}
+}
+
+func (repo *VoteIndexerRepository) SelectRecentMissValidatorVoteList(ctx context.Context, chainID string, window int64) ([]model.RecentValidatorVote, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.timeoutFor(chainID))
+	defer cancel()
+
+	// Make model
+	rvvList := make([]model.RecentValidatorVote, 0)
+	query, args := repo.recentMissQuery(chainID, window)
+	err := repo.reader().NewRaw(query, args...).Scan(ctx, &rvvList)
 	if err != nil {
 		return nil, err
 	}
 
+	for idx := range rvvList {
+		rvvList[idx].SetMissRate()
+		if repo.valconsPrefix == "" {
+			continue
+		}
+		consensusAddress, err := common.ConsHexToBech32(rvvList[idx].HexAddress, repo.valconsPrefix)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to convert consensus address for %s", rvvList[idx].Moniker)
+		}
+		rvvList[idx].ConsensusAddress = consensusAddress
+	}
+
 	return rvvList, nil
 }
 
-func (repo *VoteIndexerRepository) DeleteOldValidatorVoteList(chainID, retentionPeriod string) (
-	/* deleted rows */ int64,
-	/* unexpected error */ error,
-) {
-	ctx, cancel := context.WithTimeout(context.Background(), repo.sqlTimeout)
+// ExplainRecentMiss returns the query plan of SelectRecentMissValidatorVoteList for checking which indexes are used.
+// it's read-only because the query isn't executed by EXPLAIN without ANALYZE.
+func (repo *VoteIndexerRepository) ExplainRecentMiss(ctx context.Context, chainID string, window int64) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.timeoutFor(chainID))
 	defer cancel()
 
-	// Parsing retention period
-	duration, err := dbhelper.ParseRetentionPeriod(retentionPeriod)
+	query, args := repo.recentMissQuery(chainID, window)
+	planLines := make([]string, 0)
+	err := repo.reader().NewRaw("EXPLAIN (ANALYZE false) "+strings.TrimSpace(query), args...).Scan(ctx, &planLines)
 	if err != nil {
-		return 0, err
+		return "", errors.Wrapf(err, "failed to explain recent miss query")
 	}
 
-	// Calculate cutoff time duration
-	cutoffTime := time.Now().Add(duration)
+	return strings.Join(planLines, "\n"), nil
+}
+
+// SelectRecentMissByAddressID is a lean variant of SelectRecentMissValidatorVoteList without the validator_info join.
+// monikers, hex addresses and voting powers are empty, so resolve them by validator hex address ids from a cached map.
+func (repo *VoteIndexerRepository) SelectRecentMissByAddressID(ctx context.Context, chainID string, window int64) ([]model.RecentValidatorVote, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.timeoutFor(chainID))
+	defer cancel()
+
+	window = repo.normalizeWindow(window)
 
 	// Make partition table name
-	partitionTableName := dbhelper.MakePartitionTableName(IndexName, chainID)
+	partitionTableName := repo.partitionTableName(chainID)
 
-	// Query Execution
-	res, err := repo.NewDelete().
-		Model((*model.ValidatorVote)(nil)).
-		ModelTableExpr(partitionTableName).
-		Where("timestamp < ?", cutoffTime).
-		Exec(ctx)
+	// Make model
+	rvvList := make([]model.RecentValidatorVote, 0)
+	query := fmt.Sprintf(`
+	SELECT 
+		validator_hex_address_id,
+		MAX(height) AS max_height,
+		MIN(height) AS min_height,
+		COUNT(CASE WHEN status = ? THEN 1 END) AS missed,
+		COUNT(CASE WHEN status IN (?) THEN 1 END) AS commited,
+		COUNT(CASE WHEN status = ? AND NOT ? THEN 1 END) AS proposed,
+		COUNT(CASE WHEN status = ? THEN 1 END) AS unknown
+	FROM %s
+	WHERE height > ((SELECT MAX(height) FROM %s) - ?)
+	GROUP BY validator_hex_address_id;
+	`, partitionTableName, partitionTableName)
+
+	// collapse proposed status into committed when the option is enabled
+	committedStatuses := []model.VoteStatus{model.VoteStatusCommitted}
+	if repo.collapseProposed {
+		committedStatuses = append(committedStatuses, model.VoteStatusProposed)
+	}
+
+	err := repo.reader().NewRaw(query,
+		model.VoteStatusMissed, bun.In(committedStatuses), model.VoteStatusProposed, repo.collapseProposed,
+		model.VoteStatusUnknown,
+		window,
+	).Scan(ctx, &rvvList)
 	if err != nil {
-		return 0, err
+		return nil, errors.Wrapf(err, "failed to select recent miss by validator hex address id")
+	}
+
+	for idx := range rvvList {
+		rvvList[idx].SetMissRate()
+	}
+
+	return rvvList, nil
+}
+
+// SelectStatusCounts returns vote counts of a single validator over the recent window.
+// proposed votes are counted as committed when the collapsed proposed option is enabled, same as the recent vote query
+func (repo *VoteIndexerRepository) SelectStatusCounts(ctx context.Context, chainID string, window int64, validatorHexAddressID int64) (model.StatusCounts, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.timeoutFor(chainID))
+	defer cancel()
+
+	window = repo.normalizeWindow(window)
+
+	// Make partition table name
+	partitionTableName := repo.partitionTableName(chainID)
+
+	query := fmt.Sprintf(`
+	SELECT 
+		COUNT(CASE WHEN status = ? THEN 1 END) AS missed,
+		COUNT(CASE WHEN status IN (?) THEN 1 END) AS committed,
+		COUNT(CASE WHEN status = ? AND NOT ? THEN 1 END) AS proposed,
+		COUNT(*) AS total
+	FROM %s
+	WHERE validator_hex_address_id = ? AND height > ((SELECT MAX(height) FROM %s) - ?);
+	`, partitionTableName, partitionTableName)
+
+	// collapse proposed status into committed when the option is enabled
+	committedStatuses := []model.VoteStatus{model.VoteStatusCommitted}
+	if repo.collapseProposed {
+		committedStatuses = append(committedStatuses, model.VoteStatusProposed)
+	}
+
+	sc := model.StatusCounts{}
+	err := repo.reader().NewRaw(query,
+		model.VoteStatusMissed, bun.In(committedStatuses), model.VoteStatusProposed, repo.collapseProposed,
+		validatorHexAddressID, window,
+	).Scan(ctx, &sc)
+	if err != nil {
+		return model.StatusCounts{}, errors.Wrapf(err, "failed to select status counts of %d validator", validatorHexAddressID)
+	}
+
+	return sc, nil
+}
+
+// SelectOrphanVoteAddressIDs returns validator hex address ids in the partition which have no meta.validator_info row for the chain.
+// those votes are silently dropped by the validator_info join in the recent vote query, so the validators vanish from metrics.
+// NOTE: it scans the whole partition, so it's meant for an occasional reconciliation rather than the collector loop.
+func (repo *VoteIndexerRepository) SelectOrphanVoteAddressIDs(ctx context.Context, chainID string) ([]int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.timeoutFor(chainID))
+	defer cancel()
+
+	// Make partition table name
+	partitionTableName := repo.partitionTableName(chainID)
+
+	orphanIDs := make([]int64, 0)
+	query := fmt.Sprintf(`
+	SELECT DISTINCT vidx.validator_hex_address_id
+	FROM %s vidx
+	WHERE NOT EXISTS (
+		SELECT 1 FROM %s vi
+		WHERE vi.id = vidx.validator_hex_address_id AND vi.chain_info_id = vidx.chain_info_id
+	)
+	ORDER BY vidx.validator_hex_address_id;
+	`, partitionTableName, repo.metaTableName("validator_info"))
+	err := repo.reader().NewRaw(query).Scan(ctx, &orphanIDs)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to select orphan vote address ids")
 	}
 
-	rowsAffected, _ := res.RowsAffected()
-	return rowsAffected, nil
+	return orphanIDs, nil
+}
+
+// SelectMissRateLeaderboard returns top N worst validators by miss rate over the recent window.
+// ties are broken by missed count.
+func (repo *VoteIndexerRepository) SelectMissRateLeaderboard(ctx context.Context, chainID string, window int64, limit int) ([]model.RecentValidatorVote, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.timeoutFor(chainID))
+	defer cancel()
+
+	window = repo.normalizeWindow(window)
+
+	if limit <= 0 {
+		limit = DefaultLeaderboardLimit
+	}
+
+	// Make partition table name
+	partitionTableName := repo.partitionTableName(chainID)
+
+	// exclude freshly-bonded validators when the grace period is enabled
+	graceFilter, graceArgs := repo.graceFilter(partitionTableName)
+
+	// Make model
+	rvvList := make([]model.RecentValidatorVote, 0)
+	query := fmt.Sprintf(`
+	SELECT 
+		vi.id AS validator_hex_address_id,
+		vi.moniker, 
+		vi.hex_address,
+    	MAX(vidx.height) AS max_height,    
+    	MIN(vidx.height) AS min_height,
+    	COUNT(CASE WHEN status = ? THEN 1 END) AS missed,
+    	COUNT(CASE WHEN status IN (?) THEN 1 END) AS commited,
+    	COUNT(CASE WHEN status = ? AND NOT ? THEN 1 END) AS proposed,
+    	COUNT(CASE WHEN status = ? THEN 1 END) AS unknown
+	FROM %s vidx
+	JOIN %s vi ON vidx.validator_hex_address_id = vi.id
+	WHERE height > ((SELECT MAX(height) FROM %s) - ?)%s
+	GROUP BY vi.id, vi.moniker, vi.hex_address
+	ORDER BY 
+		COUNT(CASE WHEN status = ? THEN 1 END)::float8 / NULLIF(COUNT(CASE WHEN status IN (?) THEN 1 END), 0) DESC NULLS LAST,
+		missed DESC
+	LIMIT ?;
+	`, partitionTableName, repo.metaTableName("validator_info"), partitionTableName, graceFilter)
+	// collapse proposed status into committed when the option is enabled
+	committedStatuses := []model.VoteStatus{model.VoteStatusCommitted}
+	if repo.collapseProposed {
+		committedStatuses = append(committedStatuses, model.VoteStatusProposed)
+	}
+
+	args := []interface{}{
+		model.VoteStatusMissed, bun.In(committedStatuses), model.VoteStatusProposed, repo.collapseProposed,
+		model.VoteStatusUnknown,
+		window,
+	}
+	args = append(args, graceArgs...)
+	// NOTE: the miss rate is ordered with the denominator of SetMissRate, so that unknown votes don't distort the order
+	args = append(args, model.VoteStatusMissed, bun.In([]model.VoteStatus{model.VoteStatusMissed, model.VoteStatusCommitted, model.VoteStatusProposed}), limit)
+	err := repo.reader().NewRaw(query, args...).Scan(ctx, &rvvList)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to select miss rate leaderboard")
+	}
+
+	for idx := range rvvList {
+		rvvList[idx].SetMissRate()
+	}
+
+	return rvvList, nil
+}
+
+// SelectChainLivenessSummary returns total blocks, the number of validators with any miss and the worst miss rate
+// over the recent window in a single query for a dashboard
+func (repo *VoteIndexerRepository) SelectChainLivenessSummary(ctx context.Context, chainID string, window int64) (model.ChainLivenessSummary, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.timeoutFor(chainID))
+	defer cancel()
+
+	window = repo.normalizeWindow(window)
+
+	// Make partition table name
+	partitionTableName := repo.partitionTableName(chainID)
+
+	query := fmt.Sprintf(`
+	WITH recent AS (
+		SELECT validator_hex_address_id, height, status
+		FROM %s
+		WHERE height > ((SELECT MAX(height) FROM %s) - ?)
+	),
+	per_validator AS (
+		SELECT 
+			validator_hex_address_id,
+			COUNT(CASE WHEN status = ? THEN 1 END) AS missed,
+			COUNT(*) AS total
+		FROM recent
+		GROUP BY validator_hex_address_id
+	)
+	SELECT 
+		(SELECT COUNT(DISTINCT height) FROM recent) AS total_blocks,
+		(SELECT COUNT(*) FROM per_validator WHERE missed > 0) AS validators_with_miss,
+		COALESCE((SELECT MAX(missed::float8 / total) FROM per_validator), 0) AS worst_miss_rate;
+	`, partitionTableName, partitionTableName)
+
+	summary := model.ChainLivenessSummary{}
+	err := repo.reader().NewRaw(query, window, model.VoteStatusMissed).Scan(ctx, &summary)
+	if err != nil {
+		return model.ChainLivenessSummary{}, errors.Wrapf(err, "failed to select chain liveness summary")
+	}
+
+	return summary, nil
+}
+
+func (repo *VoteIndexerRepository) SelectMissingValidatorsAtHeight(ctx context.Context, chainID string, height int64) ([]model.RecentValidatorVote, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.timeoutFor(chainID))
+	defer cancel()
+
+	// Make partition table name
+	partitionTableName := repo.partitionTableName(chainID)
+
+	// Make model, the list will be empty when the height isn't indexed yet
+	rvvList := make([]model.RecentValidatorVote, 0)
+	query := fmt.Sprintf(`
+	SELECT 
+		vi.id AS validator_hex_address_id,
+		vi.moniker, 
+		vidx.height AS max_height,
+		vidx.height AS min_height,
+		1 AS missed,
+		0 AS commited,
+		0 AS proposed
+	FROM %s vidx
+	JOIN %s vi ON vidx.validator_hex_address_id = vi.id
+	WHERE vidx.height = ? AND vidx.status = ?
+	ORDER BY vi.moniker;
+	`, partitionTableName, repo.metaTableName("validator_info"))
+	err := repo.reader().NewRaw(query, height, model.VoteStatusMissed).Scan(ctx, &rvvList)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to select missing validators at %d height", height)
+	}
+
+	for idx := range rvvList {
+		rvvList[idx].SetMissRate()
+	}
+
+	return rvvList, nil
+}
+
+// SelectSilentValidators returns validators in meta.validator_info which have no vote rows at all in the recent window.
+// they are possibly jailed or brand new, which the missed counter can't express.
+func (repo *VoteIndexerRepository) SelectSilentValidators(ctx context.Context, chainID string, window int64) ([]model.RecentValidatorVote, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.timeoutFor(chainID))
+	defer cancel()
+
+	window = repo.normalizeWindow(window)
+
+	// Make partition table name
+	partitionTableName := repo.partitionTableName(chainID)
+
+	// exclude freshly-bonded validators when the grace period is enabled
+	graceFilter, graceArgs := repo.graceFilter(partitionTableName)
+
+	// Make model
+	rvvList := make([]model.RecentValidatorVote, 0)
+	query := fmt.Sprintf(`
+	SELECT 
+		vi.id AS validator_hex_address_id,
+		vi.moniker
+	FROM %s vi
+	JOIN %s ci ON vi.chain_info_id = ci.id
+	LEFT JOIN %s vidx 
+		ON vidx.validator_hex_address_id = vi.id
+		AND vidx.height > ((SELECT MAX(height) FROM %s) - ?)
+	WHERE ci.chain_id = ?%s
+	GROUP BY vi.id, vi.moniker
+	HAVING COUNT(vidx.height) = 0;
+	`, repo.metaTableName("validator_info"), repo.metaTableName("chain_info"), partitionTableName, partitionTableName, graceFilter)
+	args := append([]interface{}{window, chainID}, graceArgs...)
+	err := repo.reader().NewRaw(query, args...).Scan(ctx, &rvvList)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to select silent validators")
+	}
+
+	return rvvList, nil
+}
+
+func (repo *VoteIndexerRepository) SelectProposalCounts(ctx context.Context, chainID string, fromHeight, toHeight int64) (
+	/* proposal counts by validator hex address id */ map[int64]int64,
+	/* unexpected error */ error,
+) {
+	ctx, cancel := context.WithTimeout(ctx, repo.timeoutFor(chainID))
+	defer cancel()
+
+	// Make partition table name
+	partitionTableName := repo.partitionTableName(chainID)
+
+	vcList := make([]validatorCount, 0)
+	query := fmt.Sprintf(`
+	SELECT 
+		validator_hex_address_id, 
+		COUNT(*) AS count
+	FROM %s
+	WHERE height BETWEEN ? AND ? AND status = ?
+	GROUP BY validator_hex_address_id;
+	`, partitionTableName)
+	err := repo.reader().NewRaw(query, fromHeight, toHeight, model.VoteStatusProposed).Scan(ctx, &vcList)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to select proposal counts from %d to %d height", fromHeight, toHeight)
+	}
+
+	proposalCounts := make(map[int64]int64, len(vcList))
+	for _, vc := range vcList {
+		proposalCounts[vc.ValidatorHexAddressID] = vc.Count
+	}
+
+	return proposalCounts, nil
+}
+
+// SelectConsecutiveProposerGaps returns blocks since the last proposal of each validator which has voting power in the recent window.
+// a validator which keeps precommitting but doesn't propose for a long time may have a broken proposing path.
+// validators which didn't propose in the window are counted from the lowest height of the window, so the gap is capped by the window.
+func (repo *VoteIndexerRepository) SelectConsecutiveProposerGaps(ctx context.Context, chainID string, window int64) ([]model.ProposerGap, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.timeoutFor(chainID))
+	defer cancel()
+
+	window = repo.normalizeWindow(window)
+
+	// Make partition table name
+	partitionTableName := repo.partitionTableName(chainID)
+
+	// Make model
+	pgList := make([]model.ProposerGap, 0)
+	query := fmt.Sprintf(`
+	WITH recent AS (
+		SELECT validator_hex_address_id, height, status
+		FROM %s
+		WHERE height > ((SELECT MAX(height) FROM %s) - ?)
+	),
+	indexed AS (
+		SELECT MIN(height) AS min_height, MAX(height) AS max_height
+		FROM recent
+	),
+	last_proposal AS (
+		SELECT validator_hex_address_id, MAX(height) AS height
+		FROM recent
+		WHERE status = ?
+		GROUP BY validator_hex_address_id
+	)
+	SELECT 
+		vi.id AS validator_hex_address_id,
+		vi.moniker,
+		vi.voting_power,
+		COALESCE(lp.height, 0) AS last_proposed_height,
+		COALESCE((SELECT max_height FROM indexed) - COALESCE(lp.height, (SELECT min_height FROM indexed)), 0) AS blocks_since_last_proposal
+	FROM %s vi
+	JOIN %s ci ON vi.chain_info_id = ci.id
+	LEFT JOIN last_proposal lp ON lp.validator_hex_address_id = vi.id
+	WHERE ci.chain_id = ? AND vi.voting_power > 0
+	ORDER BY blocks_since_last_proposal DESC;
+	`, partitionTableName, partitionTableName, repo.metaTableName("validator_info"), repo.metaTableName("chain_info"))
+	err := repo.reader().NewRaw(query, window, model.VoteStatusProposed, chainID).Scan(ctx, &pgList)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to select consecutive proposer gaps")
+	}
+
+	return pgList, nil
+}
+
+// SelectRecentProposalCounts returns actual proposals of each validator which has voting power, with indexed blocks over the recent window.
+// validators which didn't propose in the window have 0 proposed, and the blocks are same in every row.
+func (repo *VoteIndexerRepository) SelectRecentProposalCounts(ctx context.Context, chainID string, window int64) ([]model.ProposalCount, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.timeoutFor(chainID))
+	defer cancel()
+
+	window = repo.normalizeWindow(window)
+
+	// Make partition table name
+	partitionTableName := repo.partitionTableName(chainID)
+
+	// Make model
+	pcList := make([]model.ProposalCount, 0)
+	query := fmt.Sprintf(`
+	WITH recent AS (
+		SELECT validator_hex_address_id, height, status
+		FROM %s
+		WHERE height > ((SELECT MAX(height) FROM %s) - ?)
+	),
+	blocks AS (
+		SELECT COUNT(DISTINCT height) AS blocks FROM recent
+	)
+	SELECT 
+		vi.id AS validator_hex_address_id,
+		vi.moniker,
+		vi.voting_power,
+		COUNT(CASE WHEN recent.status = ? THEN 1 END) AS proposed,
+		(SELECT blocks FROM blocks) AS blocks
+	FROM %s vi
+	JOIN %s ci ON vi.chain_info_id = ci.id
+	LEFT JOIN recent ON recent.validator_hex_address_id = vi.id
+	WHERE ci.chain_id = ? AND vi.voting_power > 0
+	GROUP BY vi.id, vi.moniker, vi.voting_power;
+	`, partitionTableName, partitionTableName, repo.metaTableName("validator_info"), repo.metaTableName("chain_info"))
+	err := repo.reader().NewRaw(query, window, model.VoteStatusProposed, chainID).Scan(ctx, &pcList)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to select recent proposal counts")
+	}
+
+	return pcList, nil
+}
+
+// SelectProposerDistribution returns proposal counts by validator hex address id over the recent window.
+// validators which didn't propose in the window are absent from the map.
+func (repo *VoteIndexerRepository) SelectProposerDistribution(ctx context.Context, chainID string, window int64) (map[int64]int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.timeoutFor(chainID))
+	defer cancel()
+
+	window = repo.normalizeWindow(window)
+
+	// Make partition table name
+	partitionTableName := repo.partitionTableName(chainID)
+
+	vcList := make([]validatorCount, 0)
+	query := fmt.Sprintf(`
+	SELECT 
+		validator_hex_address_id, 
+		COUNT(*) AS count
+	FROM %s
+	WHERE height > ((SELECT MAX(height) FROM %s) - ?) AND status = ?
+	GROUP BY validator_hex_address_id;
+	`, partitionTableName, partitionTableName)
+	err := repo.reader().NewRaw(query, window, model.VoteStatusProposed).Scan(ctx, &vcList)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to select proposer distribution")
+	}
+
+	proposalCounts := make(map[int64]int64, len(vcList))
+	for _, vc := range vcList {
+		proposalCounts[vc.ValidatorHexAddressID] = vc.Count
+	}
+
+	return proposalCounts, nil
+}
+
+// SelectValidatorVoteHistory returns a validator's votes ordered by height descending below the before height.
+// it's a keyset pagination, so pass the last height of the previous page as the next before height.
+// if before height is not positive, it starts from the latest height.
+func (repo *VoteIndexerRepository) SelectValidatorVoteHistory(
+	ctx context.Context,
+	chainID string,
+	validatorHexAddressID int64,
+	beforeHeight int64,
+	limit int,
+) ([]model.ValidatorVote, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.timeoutFor(chainID))
+	defer cancel()
+
+	if limit <= 0 || limit > MaxVoteHistoryLimit {
+		limit = MaxVoteHistoryLimit
+	}
+
+	if beforeHeight <= 0 {
+		beforeHeight = math.MaxInt64
+	}
+
+	// Make partition table name
+	partitionTableName := repo.partitionTableName(chainID)
+
+	vvList := make([]model.ValidatorVote, 0)
+	query := fmt.Sprintf(`
+	SELECT * FROM %s
+	WHERE validator_hex_address_id = ? AND height < ?
+	ORDER BY height DESC
+	LIMIT ?;
+	`, partitionTableName)
+	err := repo.reader().NewRaw(query, validatorHexAddressID, beforeHeight, limit).Scan(ctx, &vvList)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to select validator vote history")
+	}
+
+	return vvList, nil
+}
+
+// SelectVotePowerCoverage returns the voting power which committed the block and the total voting power at the height.
+// committed power includes both of committed and proposed validators.
+// the voting power snapshot at the height is used, and the current voting power is used for rows without snapshots.
+func (repo *VoteIndexerRepository) SelectVotePowerCoverage(ctx context.Context, chainID string, height int64) (
+	/* committed power */ int64,
+	/* total power */ int64,
+	/* unexpected error */ error,
+) {
+	ctx, cancel := context.WithTimeout(ctx, repo.timeoutFor(chainID))
+	defer cancel()
+
+	// Make partition table name
+	partitionTableName := repo.partitionTableName(chainID)
+
+	query := fmt.Sprintf(`
+	SELECT 
+		COALESCE(SUM(CASE WHEN vidx.status IN (?, ?) THEN COALESCE(vidx.validator_vote_power, vi.voting_power) ELSE 0 END), 0) AS committed_power,
+		COALESCE(SUM(COALESCE(vidx.validator_vote_power, vi.voting_power)), 0) AS total_power
+	FROM %s vidx
+	JOIN %s vi ON vidx.validator_hex_address_id = vi.id
+	WHERE vidx.height = ?;
+	`, partitionTableName, repo.metaTableName("validator_info"))
+
+	var committedPower, totalPower int64
+	err := repo.reader().NewRaw(query, model.VoteStatusCommitted, model.VoteStatusProposed, height).Scan(ctx, &committedPower, &totalPower)
+	if err != nil {
+		return 0, 0, errors.Wrapf(err, "failed to select vote power coverage at %d height", height)
+	}
+
+	return committedPower, totalPower, nil
+}
+
+func (repo *VoteIndexerRepository) DeleteOldValidatorVoteList(ctx context.Context, chainID, retentionPeriod string, batchSize int) (
+	/* deleted rows */ int64,
+	/* unexpected error */ error,
+) {
+	if batchSize <= 0 {
+		batchSize = DefaultDeleteBatchSize
+	}
+
+	// Calculate cutoff time by retention period
+	cutoffTime, err := makeCutoffTime(retentionPeriod)
+	if err != nil {
+		return 0, err
+	}
+
+	// Make partition table name
+	partitionTableName := repo.partitionTableName(chainID)
+
+	// NOTE: delete old records by chunks to avoid holding long table locks.
+	// ctid is unique only in a table, so tableoid is matched together for height sub-partitions.
+	// each chunk has its own sql timeout, so one slow chunk doesn't abort the whole cleanup
+	query := fmt.Sprintf(`
+	DELETE FROM %s WHERE (tableoid, ctid) IN (
+		SELECT tableoid, ctid FROM %s WHERE timestamp < ? LIMIT ?
+	);
+	`, partitionTableName, partitionTableName)
+
+	var totalRowsAffected int64
+	for {
+		rowsAffected, err := func() (int64, error) {
+			ctx, cancel := context.WithTimeout(ctx, repo.timeoutFor(chainID))
+			defer cancel()
+
+			if repo.archiveBeforeDelete {
+				return repo.archiveAndDeleteOldRows(ctx, partitionTableName, cutoffTime, batchSize)
+			}
+
+			// Query Execution
+			res, err := repo.NewRaw(query, cutoffTime, batchSize).Exec(ctx)
+			if err != nil {
+				return 0, err
+			}
+
+			return res.RowsAffected()
+		}()
+		if err != nil {
+			return totalRowsAffected, errors.Wrapf(err, "failed to delete old validator vote list after %d rows deleted", totalRowsAffected)
+		}
+
+		totalRowsAffected += rowsAffected
+		if rowsAffected < int64(batchSize) {
+			break
+		}
+	}
+
+	// refresh planner statistics right after a large cleanup instead of waiting for autovacuum
+	if repo.analyzeThreshold > 0 && totalRowsAffected >= repo.analyzeThreshold {
+		err := repo.AnalyzePartition(ctx, chainID)
+		if err != nil {
+			repo.logger.Warnf("failed to analyze partition after %d rows deleted: %s", totalRowsAffected, err)
+		}
+	}
+
+	return totalRowsAffected, nil
+}
+
+// DeleteOldValidatorVoteListByHeight deletes rows below the latest indexed height minus keep heights.
+// it's a height-based alternative of DeleteOldValidatorVoteList for deterministic storage bounds on chains with irregular block times.
+// NOTE: the archive option isn't applied, because archived rows are aggregated by the timestamp cutoff
+func (repo *VoteIndexerRepository) DeleteOldValidatorVoteListByHeight(ctx context.Context, chainID string, keepHeights int64) (
+	/* deleted rows */ int64,
+	/* unexpected error */ error,
+) {
+	if keepHeights <= 0 {
+		return 0, errors.Errorf("keep heights should be positive, but got %d", keepHeights)
+	}
+
+	// Make partition table name
+	partitionTableName := repo.partitionTableName(chainID)
+
+	// Calculate cutoff height by the latest indexed height
+	_, maxHeight, err := repo.SelectIndexedHeightRange(ctx, chainID)
+	if err != nil {
+		return 0, err
+	}
+	cutoffHeight := maxHeight - keepHeights
+	if cutoffHeight <= 0 {
+		return 0, nil
+	}
+
+	// NOTE: delete old records by chunks same as the time retention
+	query := fmt.Sprintf(`
+	DELETE FROM %s WHERE (tableoid, ctid) IN (
+		SELECT tableoid, ctid FROM %s WHERE height < ? LIMIT ?
+	);
+	`, partitionTableName, partitionTableName)
+
+	var totalRowsAffected int64
+	for {
+		rowsAffected, err := func() (int64, error) {
+			ctx, cancel := context.WithTimeout(ctx, repo.timeoutFor(chainID))
+			defer cancel()
+
+			// Query Execution
+			res, err := repo.NewRaw(query, cutoffHeight, DefaultDeleteBatchSize).Exec(ctx)
+			if err != nil {
+				return 0, err
+			}
+
+			return res.RowsAffected()
+		}()
+		if err != nil {
+			return totalRowsAffected, errors.Wrapf(err, "failed to delete validator vote list below %d height after %d rows deleted", cutoffHeight, totalRowsAffected)
+		}
+
+		totalRowsAffected += rowsAffected
+		if rowsAffected < int64(DefaultDeleteBatchSize) {
+			break
+		}
+	}
+
+	return totalRowsAffected, nil
+}
+
+// WithAnalyzeThreshold returns a shallow copy of the repository which runs ANALYZE on the partition
+// when the time retention deleted more rows than the threshold. 0 disables it, it's opt-in for avoiding extra load.
+func (repo *VoteIndexerRepository) WithAnalyzeThreshold(threshold int64) VoteIndexerRepository {
+	newRepo := *repo
+	newRepo.analyzeThreshold = threshold
+	return newRepo
+}
+
+// AnalyzePartition updates planner statistics of the partition table
+func (repo *VoteIndexerRepository) AnalyzePartition(ctx context.Context, chainID string) error {
+	ctx, cancel := context.WithTimeout(ctx, repo.timeoutFor(chainID))
+	defer cancel()
+
+	// Make partition table name
+	partitionTableName := repo.partitionTableName(chainID)
+
+	_, err := repo.NewRaw(fmt.Sprintf(`ANALYZE %s;`, partitionTableName)).Exec(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "failed to analyze %s", partitionTableName)
+	}
+
+	return nil
+}
+
+// EnsureRetentionIndex creates the timestamp index on the partition table for the time retention deletes.
+// without this index, DeleteOldValidatorVoteList does a full scan. it's a no-op when the index already exists.
+func (repo *VoteIndexerRepository) EnsureRetentionIndex(ctx context.Context, chainID string) error {
+	ctx, cancel := context.WithTimeout(ctx, repo.timeoutFor(chainID))
+	defer cancel()
+
+	// Make partition table name
+	partitionTableName := repo.partitionTableName(chainID)
+
+	// NOTE: index name can't be qualified by schema, it's created in the same schema with the table
+	retentionIndexName := fmt.Sprintf("%s_timestamp_idx", partitionTableName[strings.Index(partitionTableName, ".")+1:])
+
+	query := fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %s ON %s (timestamp);`, retentionIndexName, partitionTableName)
+	_, err := repo.NewRaw(query).Exec(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "failed to ensure retention index on %s", partitionTableName)
+	}
+
+	return nil
+}
+
+// SelectValidatorVoteStreak counts the consecutive latest rows of the validator which share the most recent status,
+// the status is the raw status value of model.VoteStatus
+func (repo *VoteIndexerRepository) SelectValidatorVoteStreak(ctx context.Context, chainID string, validatorHexAddressID int64) (
+	/* current streak */ int,
+	/* status of the streak */ int,
+	/* unexpected error */ error,
+) {
+	ctx, cancel := context.WithTimeout(ctx, repo.timeoutFor(chainID))
+	defer cancel()
+
+	// Make partition table name
+	partitionTableName := repo.partitionTableName(chainID)
+
+	// NOTE: the streak is the number of rows above the latest height which has a different status from the most recent row.
+	// when the partition has no rows for the validator yet, it returns 0 streak and 0 status.
+	query := fmt.Sprintf(`
+	WITH vv AS (
+		SELECT height, status FROM %s WHERE validator_hex_address_id = ?
+	), latest AS (
+		SELECT status FROM vv ORDER BY height DESC LIMIT 1
+	)
+	SELECT
+		COUNT(*) AS streak,
+		COALESCE((SELECT status FROM latest), 0) AS status
+	FROM vv
+	WHERE height > COALESCE((SELECT MAX(height) FROM vv WHERE status <> (SELECT status FROM latest)), -1);
+	`, partitionTableName)
+
+	var streak, status int
+	err := repo.reader().NewRaw(query, validatorHexAddressID).Scan(ctx, &streak, &status)
+	if err != nil {
+		return 0, 0, errors.Wrapf(err, "failed to select validator vote streak")
+	}
+
+	return streak, status, nil
+}
+
+// SelectValidatorUptimeSLA counts signed votes over a calendar period for SLA reports.
+// it filters on the timestamp column to use the retention index, and proposed votes are counted as signed.
+func (repo *VoteIndexerRepository) SelectValidatorUptimeSLA(ctx context.Context, chainID string, validatorHexAddressID int64, from, to time.Time) (
+	/* signed votes */ int64,
+	/* total votes */ int64,
+	/* unexpected error */ error,
+) {
+	ctx, cancel := context.WithTimeout(ctx, repo.timeoutFor(chainID))
+	defer cancel()
+
+	if !from.Before(to) {
+		return 0, 0, errors.Errorf("invalid sla period: from %s is not before to %s", from, to)
+	}
+
+	// Make partition table name
+	partitionTableName := repo.partitionTableName(chainID)
+
+	query := fmt.Sprintf(`
+	SELECT
+		COUNT(CASE WHEN status IN (?) THEN 1 END) AS signed,
+		COUNT(*) AS total
+	FROM %s
+	WHERE validator_hex_address_id = ? AND timestamp >= ? AND timestamp < ?;
+	`, partitionTableName)
+
+	var signed, total int64
+	err := repo.reader().
+		NewRaw(query, bun.In([]model.VoteStatus{model.VoteStatusCommitted, model.VoteStatusProposed}), validatorHexAddressID, from, to).
+		Scan(ctx, &signed, &total)
+	if err != nil {
+		return 0, 0, errors.Wrapf(err, "failed to select validator uptime sla from %s to %s", from, to)
+	}
+
+	return signed, total, nil
+}
+
+// SelectHourlyStatusCounts returns vote counts of the validator by hour in [from, to) ordered by the hour.
+// hours without any rows are absent from the list.
+func (repo *VoteIndexerRepository) SelectHourlyStatusCounts(ctx context.Context, chainID string, validatorHexAddressID int64, from, to time.Time) ([]model.HourlyVoteBucket, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.timeoutFor(chainID))
+	defer cancel()
+
+	if !from.Before(to) {
+		return nil, errors.Errorf("invalid period: from %s is not before to %s", from, to)
+	}
+
+	// Make partition table name
+	partitionTableName := repo.partitionTableName(chainID)
+
+	// Make model
+	bucketList := make([]model.HourlyVoteBucket, 0)
+	query := fmt.Sprintf(`
+	SELECT
+		date_trunc('hour', timestamp AT TIME ZONE 'UTC') AT TIME ZONE 'UTC' AS hour,
+		COUNT(CASE WHEN status = ? THEN 1 END) AS missed,
+		COUNT(CASE WHEN status = ? THEN 1 END) AS committed,
+		COUNT(CASE WHEN status = ? THEN 1 END) AS proposed
+	FROM %s
+	WHERE validator_hex_address_id = ? AND timestamp >= ? AND timestamp < ?
+	GROUP BY hour
+	ORDER BY hour ASC;
+	`, partitionTableName)
+	err := repo.reader().NewRaw(query,
+		model.VoteStatusMissed, model.VoteStatusCommitted, model.VoteStatusProposed,
+		validatorHexAddressID, from, to,
+	).Scan(ctx, &bucketList)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to select hourly status counts from %s to %s", from, to)
+	}
+
+	for idx := range bucketList {
+		bucketList[idx].Hour = bucketList[idx].Hour.UTC()
+	}
+
+	return bucketList, nil
+}
+
+// SelectParticipationByHeight returns signed and total validator counts of each indexed height in [from, to].
+// the total is distinct validators stored at the height except unknown rows, so it's only meaningful in the network mode.
+// heights which aren't indexed are absent from the list
+func (repo *VoteIndexerRepository) SelectParticipationByHeight(ctx context.Context, chainID string, fromHeight, toHeight int64) ([]model.HeightParticipation, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.timeoutFor(chainID))
+	defer cancel()
+
+	if toHeight < fromHeight {
+		return nil, errors.Errorf("invalid height range: from %d is over to %d", fromHeight, toHeight)
+	}
+	if toHeight-fromHeight+1 > MaxParticipationHeightRange {
+		return nil, errors.Errorf("invalid height range: %d heights are over the max range %d", toHeight-fromHeight+1, MaxParticipationHeightRange)
+	}
+
+	// Make partition table name
+	partitionTableName := repo.partitionTableName(chainID)
+
+	// Make model
+	hpList := make([]model.HeightParticipation, 0)
+	query := fmt.Sprintf(`
+	SELECT 
+		height,
+		COUNT(DISTINCT CASE WHEN status IN (?, ?) THEN validator_hex_address_id END) AS signed,
+		COUNT(DISTINCT validator_hex_address_id) AS total
+	FROM %s
+	WHERE height BETWEEN ? AND ? AND status <> ?
+	GROUP BY height
+	ORDER BY height ASC;
+	`, partitionTableName)
+	err := repo.reader().NewRaw(query,
+		model.VoteStatusCommitted, model.VoteStatusProposed,
+		fromHeight, toHeight, model.VoteStatusUnknown,
+	).Scan(ctx, &hpList)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to select participation from %d to %d height", fromHeight, toHeight)
+	}
+
+	for idx := range hpList {
+		hpList[idx].SetRate()
+	}
+
+	return hpList, nil
+}
+
+// SelectLongestDowntime returns the single longest consecutive missed streak of each validator in the time range.
+// a streak is a maximal run of missed rows ordered by height, so any other status including unknown breaks it.
+// ties are broken by the earlier start height. the range is bounded by MaxDowntimeRange because it scans the whole range.
+func (repo *VoteIndexerRepository) SelectLongestDowntime(ctx context.Context, chainID string, from, to time.Time) ([]model.DowntimeEvent, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.timeoutFor(chainID))
+	defer cancel()
+
+	if !from.Before(to) {
+		return nil, errors.Errorf("invalid period: from %s is not before to %s", from, to)
+	}
+	if to.Sub(from) > MaxDowntimeRange {
+		return nil, errors.Errorf("invalid period: the range %s is over the max downtime range %s", to.Sub(from), MaxDowntimeRange)
+	}
+
+	// Make partition table name
+	partitionTableName := repo.partitionTableName(chainID)
+
+	// Make model
+	deList := make([]model.DowntimeEvent, 0)
+	query := fmt.Sprintf(`
+	WITH vv AS (
+		SELECT 
+			validator_hex_address_id, height, timestamp, status,
+			ROW_NUMBER() OVER (PARTITION BY validator_hex_address_id ORDER BY height)
+				- ROW_NUMBER() OVER (PARTITION BY validator_hex_address_id, status ORDER BY height) AS grp
+		FROM %s
+		WHERE timestamp >= ? AND timestamp < ?
+	), runs AS (
+		SELECT 
+			validator_hex_address_id,
+			MIN(height) AS start_height,
+			MAX(height) AS end_height,
+			MIN(timestamp) AS start_time,
+			MAX(timestamp) AS end_time,
+			COUNT(*) AS missed_blocks
+		FROM vv
+		WHERE status = ?
+		GROUP BY validator_hex_address_id, grp
+	), ranked AS (
+		SELECT 
+			*,
+			ROW_NUMBER() OVER (PARTITION BY validator_hex_address_id ORDER BY missed_blocks DESC, start_height ASC) AS rn
+		FROM runs
+	)
+	SELECT 
+		r.validator_hex_address_id,
+		vi.moniker,
+		r.start_height,
+		r.end_height,
+		r.start_time,
+		r.end_time,
+		r.missed_blocks
+	FROM ranked r
+	JOIN %s vi ON r.validator_hex_address_id = vi.id
+	WHERE r.rn = 1
+	ORDER BY r.missed_blocks DESC, vi.moniker;
+	`, partitionTableName, repo.metaTableName("validator_info"))
+	err := repo.reader().NewRaw(query, from, to, model.VoteStatusMissed).Scan(ctx, &deList)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to select longest downtime from %s to %s", from, to)
+	}
+
+	for idx := range deList {
+		deList[idx].SetDuration()
+	}
+
+	return deList, nil
+}
+
+// DeleteOldValidatorVoteListAll runs the time retention on every indexed chain which has its voteindexer partition table.
+// it continues past individual chain failures and returns an aggregated error with the deleted rows by chain id.
+func (repo *VoteIndexerRepository) DeleteOldValidatorVoteListAll(ctx context.Context, retentionPeriod string) (
+	/* deleted rows by chain id */ map[string]int64,
+	/* aggregated error */ error,
+) {
+	chainInfoList, err := repo.SelectIndexedChains()
+	if err != nil {
+		return nil, err
+	}
+
+	chainIDs := make([]string, 0, len(chainInfoList))
+	for _, ci := range chainInfoList {
+		chainIDs = append(chainIDs, ci.ChainID)
+	}
+	existingChainIDs, err := repo.selectExistingPartitionChainIDs(ctx, chainIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	deletedRows := make(map[string]int64)
+	errMessages := make([]string, 0)
+	for _, ci := range chainInfoList {
+		// skip chains which don't have any voteindexer partition table
+		if !existingChainIDs[ci.ChainID] {
+			continue
+		}
+
+		rowsAffected, err := repo.DeleteOldValidatorVoteList(ctx, ci.ChainID, retentionPeriod, DefaultDeleteBatchSize)
+		deletedRows[ci.ChainID] = rowsAffected
+		if err != nil {
+			errMessages = append(errMessages, fmt.Sprintf("%s: %s", ci.ChainID, err))
+		}
+	}
+
+	if len(errMessages) > 0 {
+		return deletedRows, errors.Errorf("failed to delete old validator vote list in %d chains: %s", len(errMessages), strings.Join(errMessages, "; "))
+	}
+
+	return deletedRows, nil
+}
+
+// SelectValidatorCrossChainMiss aggregates an operator's miss rate on each chain over the recent window.
+// validatorHexAddresses is validator hex address ids of the operator by chain id, because ids are issued per chain.
+// chains without any partition table are skipped, and it continues past individual chain failures
+// and returns an aggregated error with the results of the other chains. the results are ordered by chain id.
+func (repo *VoteIndexerRepository) SelectValidatorCrossChainMiss(ctx context.Context, validatorHexAddresses map[string]int64, window int64) ([]model.CrossChainMiss, error) {
+	window = repo.normalizeWindow(window)
+
+	chainIDs := make([]string, 0, len(validatorHexAddresses))
+	for chainID := range validatorHexAddresses {
+		chainIDs = append(chainIDs, chainID)
+	}
+	sort.Strings(chainIDs)
+
+	existingChainIDs, err := repo.selectExistingPartitionChainIDs(ctx, chainIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	ccmList := make([]model.CrossChainMiss, 0, len(chainIDs))
+	errMessages := make([]string, 0)
+	for _, chainID := range chainIDs {
+		// skip chains which don't have any voteindexer partition table
+		if !existingChainIDs[chainID] {
+			continue
+		}
+		partitionTableName := repo.partitionTableName(chainID)
+
+		ccm, err := func() (model.CrossChainMiss, error) {
+			ctx, cancel := context.WithTimeout(ctx, repo.timeoutFor(chainID))
+			defer cancel()
+
+			query := fmt.Sprintf(`
+			SELECT 
+				vi.id AS validator_hex_address_id,
+				vi.moniker,
+				COUNT(CASE WHEN vidx.status = ? THEN 1 END) AS missed,
+				COUNT(vidx.height) AS total
+			FROM %s vi
+			LEFT JOIN %s vidx 
+				ON vidx.validator_hex_address_id = vi.id
+				AND vidx.height > ((SELECT MAX(height) FROM %s) - ?)
+			WHERE vi.id = ?
+			GROUP BY vi.id, vi.moniker;
+			`, repo.metaTableName("validator_info"), partitionTableName, partitionTableName)
+
+			ccm := model.CrossChainMiss{}
+			err := repo.reader().NewRaw(query, model.VoteStatusMissed, window, validatorHexAddresses[chainID]).Scan(ctx, &ccm)
+			return ccm, err
+		}()
+		if err != nil {
+			errMessages = append(errMessages, fmt.Sprintf("%s: %s", chainID, err))
+			continue
+		}
+
+		ccm.ChainID = chainID
+		if ccm.TotalCount > 0 {
+			ccm.MissRate = float64(ccm.MissedCount) / float64(ccm.TotalCount)
+		}
+		ccmList = append(ccmList, ccm)
+	}
+
+	if len(errMessages) > 0 {
+		return ccmList, errors.Errorf("failed to select cross chain miss in %d chains: %s", len(errMessages), strings.Join(errMessages, "; "))
+	}
+
+	return ccmList, nil
+}
+
+// SelectRecentMissMultiChain runs the recent vote query of each chain concurrently with bounded workers.
+// each query has its own sql timeout. failed chains are absent from the result map and reported in an aggregated error
+func (repo *VoteIndexerRepository) SelectRecentMissMultiChain(ctx context.Context, chainIDs []string, window int64) (map[string][]model.RecentValidatorVote, error) {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, DefaultMultiChainWorkers)
+
+	rvvListMap := make(map[string][]model.RecentValidatorVote, len(chainIDs))
+	errMessages := make([]string, 0)
+	for _, chainID := range chainIDs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(chainID string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			rvvList, err := repo.SelectRecentMissValidatorVoteList(ctx, chainID, window)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errMessages = append(errMessages, fmt.Sprintf("%s: %s", chainID, err))
+				return
+			}
+			rvvListMap[chainID] = rvvList
+		}(chainID)
+	}
+	wg.Wait()
+
+	if len(errMessages) > 0 {
+		sort.Strings(errMessages)
+		return rvvListMap, errors.Errorf("failed to select recent miss in %d chains: %s", len(errMessages), strings.Join(errMessages, "; "))
+	}
+
+	return rvvListMap, nil
+}
+
+// select chain ids of which partition table resolves by the configured partition naming,
+// so that it works with the tenant schema and the partition name func without matching catalog names
+func (repo *VoteIndexerRepository) selectExistingPartitionChainIDs(ctx context.Context, chainIDs []string) (map[string]bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, repo.sqlTimeout)
+	defer cancel()
+
+	tableNames := make([]string, 0, len(chainIDs))
+	for _, chainID := range chainIDs {
+		tableNames = append(tableNames, repo.partitionTableName(chainID))
+	}
+
+	existingTableNames := make([]string, 0)
+	err := repo.NewRaw(`
+	SELECT table_name
+	FROM unnest(?::text[]) AS table_name
+	WHERE to_regclass(table_name) IS NOT NULL;
+	`, pgdialect.Array(tableNames)).Scan(ctx, &existingTableNames)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to check partition tables of %s", repo.indexName)
+	}
+
+	existingTableNameMap := make(map[string]bool, len(existingTableNames))
+	for _, tableName := range existingTableNames {
+		existingTableNameMap[tableName] = true
+	}
+
+	existingChainIDs := make(map[string]bool, len(existingTableNames))
+	for idx, chainID := range chainIDs {
+		if existingTableNameMap[tableNames[idx]] {
+			existingChainIDs[chainID] = true
+		} else if repo.logger != nil {
+			repo.logger.Infof("skipped %s chain, %s partition table doesn't exist", chainID, tableNames[idx])
+		}
+	}
+
+	return existingChainIDs, nil
+}
+
+// CountOldValidatorVoteList is a dry-run of DeleteOldValidatorVoteList, it only counts the rows to be deleted
+func (repo *VoteIndexerRepository) CountOldValidatorVoteList(ctx context.Context, chainID, retentionPeriod string) (
+	/* candidate rows */ int64,
+	/* unexpected error */ error,
+) {
+	ctx, cancel := context.WithTimeout(ctx, repo.timeoutFor(chainID))
+	defer cancel()
+
+	// Calculate cutoff time by retention period
+	cutoffTime, err := makeCutoffTime(retentionPeriod)
+	if err != nil {
+		return 0, err
+	}
+
+	// Make partition table name
+	partitionTableName := repo.partitionTableName(chainID)
+
+	// Query Execution
+	count, err := repo.reader().NewSelect().
+		Model((*model.ValidatorVote)(nil)).
+		ModelTableExpr(partitionTableName).
+		Where("timestamp < ?", cutoffTime).
+		Count(ctx)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to count old validator vote list")
+	}
+
+	return int64(count), nil
+}
+
+// make cutoff time for the time retention, it's shared between the delete and the dry-run
+func makeCutoffTime(retentionPeriod string) (time.Time, error) {
+	// Parsing retention period
+	duration, err := dbhelper.ParseRetentionPeriod(retentionPeriod)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	// Calculate cutoff time duration
+	return time.Now().Add(duration), nil
 }
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -14,8 +15,8 @@ var (
 
 func TestXxx(t *testing.T) {
 	_ = testutil.SetupForTest()
-	repo := NewRepository(testutil.TestIndexerDB, 10*time.Second)
-	list, err := repo.SelectRecentMissValidatorVoteList("althea_258432_1")
+	repo := NewRepository(testutil.TestIndexerDB, 10*time.Second, nil)
+	list, err := repo.SelectRecentMissValidatorVoteList(context.Background(), "althea_258432_1", DefaultRecentWindow)
 	if err != nil {
 		t.Logf("unexpeced err: %s", err)
 	}
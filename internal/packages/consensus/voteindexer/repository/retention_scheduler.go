@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// RetentionResultFunc receives the result of each scheduled retention for metrics or logging
+type RetentionResultFunc func(chainID string, deletedRows int64, err error)
+
+// RunRetentionScheduler runs DeleteOldValidatorVoteList of every chain on the base interval until the context is canceled.
+// first runs are spread evenly across the interval, and each wait adds a random jitter in [0, jitter),
+// so that cleanups of dozens of partitions don't collide and spike the database load.
+// NOTE: it blocks, so run it in a goroutine. nil onResult ignores results
+func (repo *VoteIndexerRepository) RunRetentionScheduler(
+	ctx context.Context,
+	chainIDs []string,
+	retentionPeriod string,
+	interval, jitter time.Duration,
+	onResult RetentionResultFunc,
+) {
+	var wg sync.WaitGroup
+	for idx, chainID := range chainIDs {
+		wg.Add(1)
+		go func(idx int, chainID string) {
+			defer wg.Done()
+
+			wait := staggerOffset(idx, len(chainIDs), interval)
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(wait + randomJitter(jitter)):
+				}
+
+				deletedRows, err := repo.DeleteOldValidatorVoteList(ctx, chainID, retentionPeriod, DefaultDeleteBatchSize)
+				if onResult != nil {
+					onResult(chainID, deletedRows, err)
+				}
+				wait = interval
+			}
+		}(idx, chainID)
+	}
+	wg.Wait()
+}
+
+// staggerOffset returns the first run offset of the idx-th chain for spreading chains evenly across the interval
+func staggerOffset(idx, total int, interval time.Duration) time.Duration {
+	if total <= 0 || interval <= 0 {
+		return 0
+	}
+	return interval * time.Duration(idx) / time.Duration(total)
+}
+
+// randomJitter returns a random duration in [0, jitter), 0 when the jitter is disabled
+func randomJitter(jitter time.Duration) time.Duration {
+	if jitter <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(jitter)))
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStaggerOffset(t *testing.T) {
+	interval := time.Hour
+	total := 4
+
+	offsets := make([]time.Duration, 0, total)
+	for idx := 0; idx < total; idx++ {
+		offsets = append(offsets, staggerOffset(idx, total, interval))
+	}
+	assert.Equal(t, []time.Duration{0, 15 * time.Minute, 30 * time.Minute, 45 * time.Minute}, offsets)
+
+	// disabled cases
+	assert.Equal(t, time.Duration(0), staggerOffset(1, 0, interval))
+	assert.Equal(t, time.Duration(0), staggerOffset(1, total, 0))
+}
+
+func TestRandomJitter(t *testing.T) {
+	assert.Equal(t, time.Duration(0), randomJitter(0))
+
+	jitter := time.Minute
+	for i := 0; i < 100; i++ {
+		d := randomJitter(jitter)
+		assert.GreaterOrEqual(t, d, time.Duration(0))
+		assert.Less(t, d, jitter)
+	}
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"database/sql/driver"
+	"io"
+	"net"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/uptrace/bun/driver/pgdriver"
+)
+
+const (
+	// default max attempts for transient db errors like failovers
+	DefaultMaxRetries = 3
+
+	// base delay of the exponential backoff, 100ms -> 200ms -> 400ms ...
+	retryBaseDelay = 100 * time.Millisecond
+)
+
+// WithMaxRetries returns a shallow copy of the repository with overridden max attempts for transient db errors.
+// 1 or less means no retry.
+func (repo *VoteIndexerRepository) WithMaxRetries(maxRetries int) VoteIndexerRepository {
+	newRepo := *repo
+	newRepo.maxRetries = maxRetries
+	return newRepo
+}
+
+// retry runs the fn with exponential backoff only when the error is transient.
+// each attempt has its own sql timeout, so one failed attempt doesn't consume the budget for the next one.
+func (repo *VoteIndexerRepository) retry(ctx context.Context, logger *logrus.Entry, op string, fn func(ctx context.Context) error) error {
+	maxRetries := repo.maxRetries
+	if maxRetries < 1 {
+		maxRetries = 1
+	}
+
+	var err error
+	for attempt := 1; attempt <= maxRetries; attempt++ {
+		err = func() error {
+			ctx, cancel := context.WithTimeout(ctx, repo.sqlTimeout)
+			defer cancel()
+			return fn(ctx)
+		}()
+		if err == nil || !isTransientError(err) || attempt == maxRetries {
+			return err
+		}
+
+		delay := retryBaseDelay << (attempt - 1)
+		logger.Warnf("failed to %s by transient error, it will be retried after %s (%d/%d): %s", op, delay, attempt, maxRetries, err)
+
+		select {
+		case <-ctx.Done():
+			return errors.Wrapf(ctx.Err(), "canceled while retrying %s", op)
+		case <-time.After(delay):
+		}
+	}
+
+	return err
+}
+
+// isTransientError reports whether the error is connection-level or serialization error which is safe to retry.
+// constraint violations and other query errors are never retried.
+func isTransientError(err error) bool {
+	var pgErr pgdriver.Error
+	if errors.As(err, &pgErr) {
+		if pgErr.IntegrityViolation() {
+			return false
+		}
+
+		code := pgErr.Field('C')
+		switch {
+		// serialization_failure, deadlock_detected
+		case code == "40001" || code == "40P01":
+			return true
+		// connection exception class
+		case len(code) == 5 && code[:2] == "08":
+			return true
+		// admin_shutdown, crash_shutdown, cannot_connect_now
+		case code == "57P01" || code == "57P02" || code == "57P03":
+			return true
+		default:
+			return false
+		}
+	}
+
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
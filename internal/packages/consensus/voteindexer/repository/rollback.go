@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"database/sql/driver"
+	"io"
+	"net"
+
+	"github.com/pkg/errors"
+	"github.com/uptrace/bun/driver/pgdriver"
+)
+
+// error classes of rolled back insert transactions
+const (
+	RollbackReasonConstraint = "constraint"
+	RollbackReasonTimeout    = "timeout"
+	RollbackReasonConnection = "connection"
+	RollbackReasonOther      = "other"
+)
+
+var RollbackReasons = []string{RollbackReasonConstraint, RollbackReasonTimeout, RollbackReasonConnection, RollbackReasonOther}
+
+// TxRollbackError is returned when the insert transaction was rolled back, the reason distinguishes
+// transient db issues from persistent schema problems
+type TxRollbackError struct {
+	Reason string
+	Err    error
+}
+
+func (e *TxRollbackError) Error() string {
+	return "transaction rolled back by " + e.Reason + ": " + e.Err.Error()
+}
+
+func (e *TxRollbackError) Unwrap() error {
+	return e.Err
+}
+
+func newTxRollbackError(err error) *TxRollbackError {
+	return &TxRollbackError{Reason: rollbackReason(err), Err: err}
+}
+
+func rollbackReason(err error) string {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return RollbackReasonTimeout
+	}
+
+	var pgErr pgdriver.Error
+	if errors.As(err, &pgErr) {
+		code := pgErr.Field('C')
+		switch {
+		case pgErr.IntegrityViolation():
+			return RollbackReasonConstraint
+		// query_canceled by statement timeout
+		case code == "57014":
+			return RollbackReasonTimeout
+		// connection exception class, admin_shutdown, crash_shutdown, cannot_connect_now
+		case len(code) == 5 && code[:2] == "08", code == "57P01" || code == "57P02" || code == "57P03":
+			return RollbackReasonConnection
+		default:
+			return RollbackReasonOther
+		}
+	}
+
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return RollbackReasonConnection
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return RollbackReasonTimeout
+		}
+		return RollbackReasonConnection
+	}
+
+	return RollbackReasonOther
+}
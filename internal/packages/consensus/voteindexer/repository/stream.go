@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/cosmostation/cvms/internal/packages/consensus/voteindexer/model"
+	"github.com/pkg/errors"
+	"github.com/uptrace/bun/dialect/pgdialect"
+)
+
+// selected columns of a validator vote, it's derived from the bun model so that new columns are exported
+var validatorVoteSelectColumns = func() string {
+	fields := pgdialect.New().Tables().Get(reflect.TypeOf(model.ValidatorVote{})).Fields
+	columns := make([]string, 0, len(fields))
+	for _, field := range fields {
+		columns = append(columns, string(field.SQLName))
+	}
+	return strings.Join(columns, ", ")
+}()
+
+// StreamValidatorVotes iterates the whole vote history of the chain by height and invokes the callback per row
+// without loading all rows into memory, for exporting into files.
+// NOTE: the sql timeout isn't applied because an export can take long, so cancel the ctx to stop the scan.
+// the scan stops on the first callback error and it's returned as is.
+func (repo *VoteIndexerRepository) StreamValidatorVotes(ctx context.Context, chainID string, fn func(model.ValidatorVote) error) error {
+	// Make partition table name
+	partitionTableName := repo.partitionTableName(chainID)
+
+	query := fmt.Sprintf(`
+	SELECT %s
+	FROM %s
+	ORDER BY height ASC, validator_hex_address_id ASC;
+	`, validatorVoteSelectColumns, partitionTableName)
+	rows, err := repo.reader().QueryContext(ctx, query)
+	if err != nil {
+		return errors.Wrapf(err, "failed to query validator votes from %s", partitionTableName)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		vv := model.ValidatorVote{}
+		err := repo.reader().ScanRow(ctx, rows, &vv)
+		if err != nil {
+			return errors.Wrap(err, "failed to scan validator vote")
+		}
+
+		err = fn(vv)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return errors.Wrap(err, "failed to iterate validator votes")
+	}
+
+	return nil
+}
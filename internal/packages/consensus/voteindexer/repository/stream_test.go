@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatorVoteSelectColumns(t *testing.T) {
+	assert.Equal(t,
+		`"id", "chain_info_id", "height", "validator_hex_address_id", "status", "timestamp", "validator_vote_power", "raw_flag", "block_hash"`,
+		validatorVoteSelectColumns,
+	)
+}
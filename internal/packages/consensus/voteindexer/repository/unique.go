@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NOTE: replayed heights after a crash are skipped by this conflict clause.
+// it requires the unique index on ValidatorVoteUniqueColumns, see ValidatorVoteUniqueIndexDDL
+// and the uniq_block_missed_validator_hex_address_by_height constraint in docker/postgres/schema/02-init-voteindexer.sql
+var onConflictDoNothing = fmt.Sprintf("CONFLICT (%s) DO NOTHING", strings.Join(ValidatorVoteUniqueColumns(), ", "))
+
+// ValidatorVoteUniqueColumns returns the columns of the unique index for a validator vote.
+// the conflict clause of inserts and the index definition are both made from it for avoiding drift.
+func ValidatorVoteUniqueColumns() []string {
+	return []string{"chain_info_id", "validator_hex_address_id", "height"}
+}
+
+// ValidatorVoteUniqueIndexDDL returns the DDL for operators to create the unique index matched with the conflict clause.
+// the table name is a parent or partition table name like public.voteindexer
+func ValidatorVoteUniqueIndexDDL(tableName string) string {
+	indexName := strings.ReplaceAll(tableName, ".", "_") + "_uniq_vote_idx"
+	return fmt.Sprintf("CREATE UNIQUE INDEX IF NOT EXISTS %s ON %s (%s);",
+		indexName, tableName, strings.Join(ValidatorVoteUniqueColumns(), ", "))
+}
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatorVoteUniqueIndexDDL(t *testing.T) {
+	ddl := ValidatorVoteUniqueIndexDDL("public.voteindexer")
+	assert.Equal(t, "CREATE UNIQUE INDEX IF NOT EXISTS public_voteindexer_uniq_vote_idx ON public.voteindexer (chain_info_id, validator_hex_address_id, height);", ddl)
+
+	// the conflict target should be same with the index columns
+	ddlColumns := regexp.MustCompile(`\(([^)]+)\);$`).FindStringSubmatch(ddl)
+	conflictColumns := regexp.MustCompile(`^CONFLICT \(([^)]+)\) DO NOTHING$`).FindStringSubmatch(onConflictDoNothing)
+	if assert.Len(t, ddlColumns, 2) && assert.Len(t, conflictColumns, 2) {
+		assert.Equal(t, ddlColumns[1], conflictColumns[1])
+	}
+}
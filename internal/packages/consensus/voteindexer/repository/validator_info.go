@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	idxmodel "github.com/cosmostation/cvms/internal/common/indexer/model"
+	"github.com/pkg/errors"
+	"github.com/uptrace/bun"
+)
+
+// SyncValidatorInfo reconciles meta.validator_info of the chain with the current on-chain validator set in one transaction.
+// validators are upserted by hex address, and rows which aren't changed aren't counted as updated.
+// validators absent from the set are kept for the vote rows referencing them, but their voting powers are reset into 0,
+// so that they are excluded from dashboards as stale validators. the stale ones are counted as updated.
+func (repo *VoteIndexerRepository) SyncValidatorInfo(ctx context.Context, chainID string, validators []idxmodel.ValidatorInfo) (
+	/* inserted rows */ int,
+	/* updated rows */ int,
+	/* unexpected error */ error,
+) {
+	ctx, cancel := context.WithTimeout(ctx, repo.timeoutFor(chainID))
+	defer cancel()
+
+	if len(validators) == 0 {
+		return 0, 0, errors.New("empty validator set, it may wipe out voting powers of every validators")
+	}
+
+	var chainInfoID int64
+	err := repo.NewRaw(
+		fmt.Sprintf(`SELECT id FROM %s WHERE chain_id = ?;`, repo.metaTableName("chain_info")),
+		chainID,
+	).Scan(ctx, &chainInfoID)
+	if err != nil {
+		return 0, 0, errors.Wrapf(err, "failed to select chain_info id of %s", chainID)
+	}
+
+	validatorInfoTableName := repo.metaTableName("validator_info")
+
+	// NOTE: xmax is 0 only for a newly inserted row, and no row is returned when the row isn't changed
+	upsertQuery := fmt.Sprintf(`
+	INSERT INTO %s AS vi (chain_info_id, hex_address, operator_address, moniker, voting_power)
+	VALUES (?, ?, ?, ?, ?)
+	ON CONFLICT ON CONSTRAINT uniq_hex_address_by_chain DO UPDATE
+	SET operator_address = EXCLUDED.operator_address, moniker = EXCLUDED.moniker, voting_power = EXCLUDED.voting_power
+	WHERE (vi.operator_address, vi.moniker, vi.voting_power) IS DISTINCT FROM (EXCLUDED.operator_address, EXCLUDED.moniker, EXCLUDED.voting_power)
+	RETURNING (xmax = 0) AS inserted;
+	`, validatorInfoTableName)
+	staleQuery := fmt.Sprintf(`
+	UPDATE %s SET voting_power = 0
+	WHERE chain_info_id = ? AND voting_power > 0 AND hex_address NOT IN (?);
+	`, validatorInfoTableName)
+
+	var inserted, updated int
+	err = repo.RunInTx(ctx, repo.txOptions, func(ctx context.Context, tx bun.Tx) error {
+		hexAddresses := make([]string, 0, len(validators))
+		for _, vi := range validators {
+			hexAddresses = append(hexAddresses, vi.HexAddress)
+
+			var isInserted bool
+			err := tx.NewRaw(upsertQuery, chainInfoID, vi.HexAddress, vi.OperatorAddress, vi.Moniker, vi.VotingPower).Scan(ctx, &isInserted)
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			if err != nil {
+				return errors.Wrapf(err, "failed to upsert validator info of %s", vi.HexAddress)
+			}
+
+			if isInserted {
+				inserted++
+			} else {
+				updated++
+			}
+		}
+
+		res, err := tx.NewRaw(staleQuery, chainInfoID, bun.In(hexAddresses)).Exec(ctx)
+		if err != nil {
+			return errors.Wrap(err, "failed to reset voting powers of stale validators")
+		}
+		staleRows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		updated += int(staleRows)
+
+		return nil
+	})
+	if err != nil {
+		return 0, 0, errors.Wrapf(err, "failed to sync validator info of %s", chainID)
+	}
+
+	// new validator ids should be resolved from the database again
+	repo.InvalidateValidatorInfoCache(chainID)
+
+	return inserted, updated, nil
+}
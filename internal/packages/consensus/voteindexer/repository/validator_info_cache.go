@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// default lifetime of cached validator ids, validator ids never change but removed rows should be expired eventually
+const DefaultValidatorInfoCacheTTL = 10 * time.Minute
+
+type validatorInfoCacheEntry struct {
+	id        int64
+	expiredAt time.Time
+}
+
+// ValidatorInfoCache keeps validator ids by chain id and hex address for reducing meta schema queries.
+// it's shared by shallow copies of the repository
+type ValidatorInfoCache struct {
+	sync.RWMutex
+	ttl     time.Duration
+	entries map[string]map[string]validatorInfoCacheEntry
+}
+
+func NewValidatorInfoCache(ttl time.Duration) *ValidatorInfoCache {
+	if ttl <= 0 {
+		ttl = DefaultValidatorInfoCacheTTL
+	}
+	return &ValidatorInfoCache{
+		ttl:     ttl,
+		entries: make(map[string]map[string]validatorInfoCacheEntry),
+	}
+}
+
+func (c *ValidatorInfoCache) get(chainID, hexAddress string) (int64, bool) {
+	c.RLock()
+	defer c.RUnlock()
+	entry, exist := c.entries[chainID][hexAddress]
+	if !exist || time.Now().After(entry.expiredAt) {
+		return 0, false
+	}
+	return entry.id, true
+}
+
+func (c *ValidatorInfoCache) set(chainID, hexAddress string, id int64) {
+	c.Lock()
+	defer c.Unlock()
+	if _, exist := c.entries[chainID]; !exist {
+		c.entries[chainID] = make(map[string]validatorInfoCacheEntry)
+	}
+	c.entries[chainID][hexAddress] = validatorInfoCacheEntry{id: id, expiredAt: time.Now().Add(c.ttl)}
+}
+
+// Invalidate drops all cached validator ids of the chain
+func (c *ValidatorInfoCache) Invalidate(chainID string) {
+	c.Lock()
+	defer c.Unlock()
+	delete(c.entries, chainID)
+}
+
+// WithValidatorInfoCacheTTL returns a shallow copy of the repository with a new validator info cache
+func (repo *VoteIndexerRepository) WithValidatorInfoCacheTTL(ttl time.Duration) VoteIndexerRepository {
+	newRepo := *repo
+	newRepo.vic = NewValidatorInfoCache(ttl)
+	return newRepo
+}
+
+// InvalidateValidatorInfoCache should be called when the validator set of the chain was changed
+func (repo *VoteIndexerRepository) InvalidateValidatorInfoCache(chainID string) {
+	repo.vic.Invalidate(chainID)
+}
+
+// ResolveValidatorID returns the validator_info id by the hex address, it checks the cache first and hits the db on miss
+func (repo *VoteIndexerRepository) ResolveValidatorID(ctx context.Context, chainID, hexAddress string) (int64, error) {
+	if id, exist := repo.vic.get(chainID, hexAddress); exist {
+		return id, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, repo.timeoutFor(chainID))
+	defer cancel()
+
+	query := fmt.Sprintf(`
+	SELECT vi.id
+	FROM %s vi
+	JOIN %s ci ON vi.chain_info_id = ci.id
+	WHERE ci.chain_id = ? AND vi.hex_address = ?;
+	`, repo.metaTableName("validator_info"), repo.metaTableName("chain_info"))
+
+	var id int64
+	err := repo.NewRaw(query, chainID, hexAddress).Scan(ctx, &id)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to resolve validator id of %s", hexAddress)
+	}
+
+	repo.vic.set(chainID, hexAddress, id)
+	return id, nil
+}
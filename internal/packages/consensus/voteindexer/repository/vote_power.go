@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/uptrace/bun/dialect/pgdialect"
+)
+
+// EnsureVotePowerColumn adds the nullable validator_vote_power column for voting power snapshots at the vote's height.
+// it's added on the parent table, so every partition has it. rows without any snapshot keep null,
+// and power-weighted queries fall back into the current voting power in validator_info for them.
+func (repo *VoteIndexerRepository) EnsureVotePowerColumn(ctx context.Context) error {
+	return repo.ensureColumn(ctx, "validator_vote_power", "BIGINT")
+}
+
+// BackfillVotePower fills voting power snapshots of the heights in [from, to] from powerByHeight,
+// for rows which were indexed before the collector stored snapshots on insert.
+// powerByHeight returns voting powers by validator hex address id at the height. heights with an empty map are skipped.
+// NOTE: each height is updated by its own statement and timeout, so a failure keeps the previous heights backfilled.
+func (repo *VoteIndexerRepository) BackfillVotePower(
+	ctx context.Context,
+	chainID string,
+	fromHeight, toHeight int64,
+	powerByHeight func(height int64) map[int64]int64,
+) error {
+	if toHeight < fromHeight {
+		return errors.Errorf("invalid height range: from %d is over to %d", fromHeight, toHeight)
+	}
+
+	// Make partition table name
+	partitionTableName := repo.partitionTableName(chainID)
+
+	query := fmt.Sprintf(`
+	UPDATE %s AS vidx
+	SET validator_vote_power = v.power
+	FROM (SELECT unnest(?::bigint[]) AS id, unnest(?::bigint[]) AS power) AS v
+	WHERE vidx.height = ? AND vidx.validator_hex_address_id = v.id;
+	`, partitionTableName)
+
+	for height := fromHeight; height <= toHeight; height++ {
+		powers := powerByHeight(height)
+		if len(powers) == 0 {
+			continue
+		}
+
+		ids := make([]int64, 0, len(powers))
+		votingPowers := make([]int64, 0, len(powers))
+		for id, power := range powers {
+			ids = append(ids, id)
+			votingPowers = append(votingPowers, power)
+		}
+
+		err := func() error {
+			ctx, cancel := context.WithTimeout(ctx, repo.timeoutFor(chainID))
+			defer cancel()
+
+			_, err := repo.NewRaw(query, pgdialect.Array(ids), pgdialect.Array(votingPowers), height).Exec(ctx)
+			return err
+		}()
+		if err != nil {
+			return errors.Wrapf(err, "failed to backfill vote power at %d height", height)
+		}
+	}
+
+	return nil
+}
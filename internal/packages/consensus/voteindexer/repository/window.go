@@ -0,0 +1,32 @@
+package repository
+
+// default max lookback blocks of windowed queries, it's a safety rail against full scans of a giant partition
+const DefaultMaxWindow int64 = 100_000
+
+// WithMaxWindow returns a shallow copy of the repository which clamps windows of recent queries into the max window.
+// not positive max window means the default.
+func (repo *VoteIndexerRepository) WithMaxWindow(maxWindow int64) VoteIndexerRepository {
+	newRepo := *repo
+	newRepo.maxWindow = maxWindow
+	return newRepo
+}
+
+// normalizeWindow returns the default window for a not positive window, and clamps a window over the max window
+func (repo *VoteIndexerRepository) normalizeWindow(window int64) int64 {
+	if window <= 0 {
+		return DefaultRecentWindow
+	}
+
+	maxWindow := repo.maxWindow
+	if maxWindow <= 0 {
+		maxWindow = DefaultMaxWindow
+	}
+	if window > maxWindow {
+		if repo.logger != nil {
+			repo.logger.Warnf("requested window %d is over the max window, it's clamped into %d", window, maxWindow)
+		}
+		return maxWindow
+	}
+
+	return window
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeWindow(t *testing.T) {
+	testCases := []struct {
+		name     string
+		repo     VoteIndexerRepository
+		window   int64
+		expected int64
+	}{
+		{name: "default window", repo: VoteIndexerRepository{}, window: 0, expected: DefaultRecentWindow},
+		{name: "within the default max window", repo: VoteIndexerRepository{}, window: 500, expected: 500},
+		{name: "clamped by the default max window", repo: VoteIndexerRepository{}, window: DefaultMaxWindow + 1, expected: DefaultMaxWindow},
+		{name: "clamped by the custom max window", repo: VoteIndexerRepository{maxWindow: 1000}, window: 5000, expected: 1000},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.repo.normalizeWindow(tc.window))
+		})
+	}
+}